@@ -57,6 +57,8 @@ func (c Client) NewAccountOptions(privateKey crypto.Signer, options ...NewAccoun
 }
 
 // UpdateAccount updates an existing account with the acme service.
+// Note this function is essentially deprecated and only present for backwards compatibility.
+// New programs should implement UpdateAccountOptions and RefreshAccount instead.
 func (c Client) UpdateAccount(account Account, contact ...string) (Account, error) {
 	var updateAccountReq interface{}
 
@@ -88,6 +90,92 @@ func (c Client) UpdateAccount(account Account, contact ...string) (Account, erro
 	return account, nil
 }
 
+// UpdateAccountOptions updates an existing account with the acme service given the provided options, returning the
+// account as provided by the server after the update. At least one option must be provided, to fetch the current
+// state of the account use RefreshAccount.
+func (c Client) UpdateAccountOptions(account Account, options ...UpdateAccountOptionFunc) (Account, error) {
+	updateAccountReq := UpdateAccountRequest{}
+
+	for _, opt := range options {
+		if err := opt(&account, &updateAccountReq, c); err != nil {
+			return account, err
+		}
+	}
+
+	if updateAccountReq.Contact == nil && !updateAccountReq.TermsOfServiceAgreed && len(updateAccountReq.Extensions) == 0 {
+		return account, errors.New("acme: no account updates provided")
+	}
+
+	return c.postAccount(account, updateAccountReq)
+}
+
+// RefreshAccount fetches the current state of an existing account from the acme service.
+func (c Client) RefreshAccount(account Account) (Account, error) {
+	return c.postAccount(account, noPayload)
+}
+
+// Helper function to post a payload to an account url, returning the account as provided by the server while
+// retaining any client side fields.
+func (c Client) postAccount(account Account, payload interface{}) (Account, error) {
+	updatedAccount := Account{
+		URL:                    account.URL,
+		PrivateKey:             account.PrivateKey,
+		Thumbprint:             account.Thumbprint,
+		ExternalAccountBinding: account.ExternalAccountBinding,
+	}
+
+	_, err := c.post(account.URL, account.URL, account.PrivateKey, payload, &updatedAccount, http.StatusOK)
+	if err != nil {
+		return account, err
+	}
+
+	if updatedAccount.Thumbprint == "" {
+		updatedAccount.Thumbprint, err = JWKThumbprint(updatedAccount.PrivateKey.Public())
+		if err != nil {
+			return updatedAccount, fmt.Errorf("acme: error computing account thumbprint: %v", err)
+		}
+	}
+
+	return updatedAccount, nil
+}
+
+// UnmarshalJSON decodes an account object, storing any fields not otherwise present in the Account structure
+// in the Extensions field.
+func (a *Account) UnmarshalJSON(data []byte) error {
+	type account Account
+	if err := json.Unmarshal(data, (*account)(a)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, k := range []string{"status", "contact", "orders", "delegations"} {
+		delete(fields, k)
+	}
+	if len(fields) > 0 {
+		a.Extensions = fields
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes an account update request, only including fields which are to be updated.
+func (r UpdateAccountRequest) MarshalJSON() ([]byte, error) {
+	fields := map[string]interface{}{}
+	for k, v := range r.Extensions {
+		fields[k] = v
+	}
+	if r.Contact != nil {
+		fields["contact"] = r.Contact
+	}
+	if r.TermsOfServiceAgreed {
+		fields["termsOfServiceAgreed"] = true
+	}
+	return json.Marshal(fields)
+}
+
 // AccountKeyChange rolls over an account to a new key.
 func (c Client) AccountKeyChange(account Account, newPrivateKey crypto.Signer) (Account, error) {
 	oldJwkKeyPub, err := jwkEncode(account.PrivateKey.Public())
@@ -132,13 +220,24 @@ func (c Client) DeactivateAccount(account Account) (Account, error) {
 
 // FetchOrderList fetches a list of orders from the account url provided in the account Orders field
 func (c Client) FetchOrderList(account Account) (OrderList, error) {
+	if account.Orders == "" {
+		return OrderList{}, errors.New("no order list for account")
+	}
+
+	return c.FetchOrderListPage(account, account.Orders)
+}
+
+// FetchOrderListPage fetches a single page of an order list given its url, eg the Next url of a previously fetched
+// OrderList.
+func (c Client) FetchOrderListPage(account Account, orderListURL string) (OrderList, error) {
 	orderList := OrderList{}
 
-	if account.Orders == "" {
-		return orderList, errors.New("no order list for account")
+	resp, err := c.post(orderListURL, account.URL, account.PrivateKey, "", &orderList, http.StatusOK)
+	if err != nil {
+		return orderList, err
 	}
 
-	_, err := c.post(account.Orders, account.URL, account.PrivateKey, "", &orderList, http.StatusOK)
+	orderList.Next = fetchLink(resp, "next")
 
-	return orderList, err
+	return orderList, nil
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"io"
 	"reflect"
@@ -120,6 +121,110 @@ func TestClient_UpdateAccount2(t *testing.T) {
 	}
 }
 
+func TestClient_UpdateAccountOptions(t *testing.T) {
+	account := makeAccount(t)
+
+	if _, err := testClient.UpdateAccountOptions(account); err == nil {
+		t.Fatal("expected error with no options, got none")
+	}
+
+	contact := []string{"mailto:test@test.com"}
+	updatedAccount, err := testClient.UpdateAccountOptions(account, UpdateAcctOptWithContacts(contact...), UpdateAcctOptAgreeTOS())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(updatedAccount.Contact, contact) {
+		t.Fatalf("contact mismatch, expected: %v, got: %v", contact, updatedAccount.Contact)
+	}
+	if updatedAccount.Status != "valid" {
+		t.Fatalf("expected valid account status, got: %s", updatedAccount.Status)
+	}
+	if updatedAccount.URL != account.URL || updatedAccount.PrivateKey != account.PrivateKey {
+		t.Fatalf("account url or key not retained: %+v", updatedAccount)
+	}
+
+	clearedAccount, err := testClient.UpdateAccountOptions(updatedAccount, UpdateAcctOptClearContacts())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clearedAccount.Contact) != 0 {
+		t.Fatalf("expected no contacts, got: %v", clearedAccount.Contact)
+	}
+
+	optErr := func(*Account, *UpdateAccountRequest, Client) error {
+		return errors.New("ALWAYS ERRORS")
+	}
+	if _, err := testClient.UpdateAccountOptions(account, optErr); err == nil || !strings.Contains(err.Error(), "ALWAYS") {
+		t.Fatalf("expected option error, got: %v", err)
+	}
+}
+
+func TestClient_RefreshAccount(t *testing.T) {
+	account := makeAccount(t)
+	refreshedAccount, err := testClient.RefreshAccount(Account{PrivateKey: account.PrivateKey, URL: account.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(account, refreshedAccount) {
+		t.Fatalf("account and refreshed account mismatch, expected: %+v, got: %+v", account, refreshedAccount)
+	}
+
+	if _, err := testClient.RefreshAccount(Account{PrivateKey: account.PrivateKey}); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+}
+
+func TestAccount_UnmarshalJSON(t *testing.T) {
+	var account Account
+	data := `{"status":"valid","contact":["mailto:a@b.c"],"orders":"https://x/orders","delegations":"https://x/delegations","createdAt":"now","key":{"kty":"EC"}}`
+	if err := json.Unmarshal([]byte(data), &account); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if account.Status != "valid" || account.Orders != "https://x/orders" || account.Delegations != "https://x/delegations" || len(account.Contact) != 1 {
+		t.Fatalf("known fields not decoded: %+v", account)
+	}
+	if len(account.Extensions) != 2 {
+		t.Fatalf("expected 2 extension fields, got: %v", account.Extensions)
+	}
+	if string(account.Extensions["createdAt"]) != `"now"` {
+		t.Fatalf("unexpected createdAt extension: %s", account.Extensions["createdAt"])
+	}
+}
+
+func TestUpdateAccountRequest_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		request  UpdateAccountRequest
+		expected string
+	}{
+		{
+			request:  UpdateAccountRequest{},
+			expected: `{}`,
+		},
+		{
+			request:  UpdateAccountRequest{Contact: []string{}},
+			expected: `{"contact":[]}`,
+		},
+		{
+			request: UpdateAccountRequest{
+				Contact:              []string{"mailto:a@b.c"},
+				TermsOfServiceAgreed: true,
+				Extensions:           map[string]interface{}{"hello": 1},
+			},
+			expected: `{"contact":["mailto:a@b.c"],"hello":1,"termsOfServiceAgreed":true}`,
+		},
+	}
+	for i, ct := range tests {
+		b, err := json.Marshal(ct.request)
+		if err != nil {
+			t.Fatalf("test %d unexpected error: %v", i, err)
+		}
+		if string(b) != ct.expected {
+			t.Fatalf("test %d expected %s, got: %s", i, ct.expected, string(b))
+		}
+	}
+}
+
 type errSigner struct{}
 
 func (es errSigner) Public() crypto.PublicKey {
@@ -53,6 +53,12 @@ func NewClient(directoryURL string, options ...OptionFunc) (Client, error) {
 		return acmeClient, err
 	}
 
+	if acmeClient.defaultProfile != "" {
+		if _, ok := acmeClient.dir.Meta.Profiles[acmeClient.defaultProfile]; !ok {
+			return acmeClient, fmt.Errorf("acme: default profile not advertised by directory: %v", acmeClient.defaultProfile)
+		}
+	}
+
 	return acmeClient, nil
 }
 
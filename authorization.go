@@ -10,6 +10,40 @@ func (c Client) FetchAuthorization(account Account, authURL string) (Authorizati
 		return authResp, err
 	}
 
+	authResp.URL = authURL
+	populateAuthorization(account, &authResp)
+
+	return authResp, nil
+}
+
+// NewAuthorization pre-authorizes an identifier using the newAuthz endpoint, before any order is created.
+// Returns ErrNewAuthzNotSupported if the acme server doesn't provide a newAuthz endpoint.
+// See https://tools.ietf.org/html/rfc8555#section-7.4.1
+func (c Client) NewAuthorization(account Account, identifier Identifier) (Authorization, error) {
+	if c.dir.NewAuthz == "" {
+		return Authorization{}, ErrNewAuthzNotSupported
+	}
+
+	newAuthzReq := struct {
+		Identifier Identifier `json:"identifier"`
+	}{
+		Identifier: identifier,
+	}
+	authResp := Authorization{}
+
+	resp, err := c.post(c.dir.NewAuthz, account.URL, account.PrivateKey, newAuthzReq, &authResp, http.StatusCreated, http.StatusOK)
+	if err != nil {
+		return authResp, err
+	}
+
+	authResp.URL = resp.Header.Get("Location")
+	populateAuthorization(account, &authResp)
+
+	return authResp, nil
+}
+
+// Helper function to fill in challenge key authorizations and convenience fields of an authorization.
+func populateAuthorization(account Account, authResp *Authorization) {
 	for i := 0; i < len(authResp.Challenges); i++ {
 		if authResp.Challenges[i].KeyAuthorization == "" {
 			authResp.Challenges[i].KeyAuthorization = authResp.Challenges[i].Token + "." + account.Thumbprint
@@ -22,10 +56,6 @@ func (c Client) FetchAuthorization(account Account, authURL string) (Authorizati
 		authResp.ChallengeMap[c.Type] = c
 		authResp.ChallengeTypes = append(authResp.ChallengeTypes, c.Type)
 	}
-
-	authResp.URL = authURL
-
-	return authResp, nil
 }
 
 // DeactivateAuthorization deactivate a provided authorization url from an order.
@@ -28,3 +28,38 @@ func TestClient_DeactivateAuthorization(t *testing.T) {
 		t.Fatalf("expected deactivated status, got: %s", auth.Status)
 	}
 }
+
+func TestClient_NewAuthorization(t *testing.T) {
+	account := makeAccount(t)
+	identifier := Identifier{Type: "dns", Value: randString() + ".com"}
+
+	tc2 := testClient
+	tc2.dir.NewAuthz = ""
+	if _, err := tc2.NewAuthorization(account, identifier); err != ErrNewAuthzNotSupported {
+		t.Fatalf("expected ErrNewAuthzNotSupported, got: %v", err)
+	}
+
+	if testClient.dir.NewAuthz == "" {
+		t.Skip("acme server does not support pre-authorization")
+		return
+	}
+
+	auth, err := testClient.NewAuthorization(account, identifier)
+	if err != nil {
+		t.Fatalf("unexpected error creating authorization: %v", err)
+	}
+	if auth.URL == "" {
+		t.Fatal("no authorization url")
+	}
+	if auth.Identifier != identifier {
+		t.Fatalf("identifier mismatch, expected: %+v, got: %+v", identifier, auth.Identifier)
+	}
+	if len(auth.ChallengeMap) == 0 || len(auth.ChallengeTypes) != len(auth.Challenges) {
+		t.Fatalf("challenge map not populated: %+v", auth)
+	}
+	for _, chal := range auth.Challenges {
+		if chal.KeyAuthorization == "" {
+			t.Fatalf("no key authorization on challenge: %+v", chal)
+		}
+	}
+}
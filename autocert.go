@@ -9,7 +9,6 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
-	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -62,15 +61,20 @@ type AutoCert struct {
 	// Called before updating challenges
 	PreUpdateChallengeHook func(Account, Challenge)
 
-	// Mapping of token -> keyauth
-	// Protected by a mutex, but not rwmutex because tokens are deleted once read
-	tokensLock sync.RWMutex
-	tokens     map[string][]byte
+	// Store for http-01 challenge tokens, shared with other nodes serving HTTPHandler, eg behind a load balancer
+	// If nil, tokens are stored in-process
+	TokenStore HTTP01TokenStore
+
+	// In-process token store used if TokenStore is nil
+	tokens MemoryTokenStore
 
 	// Mapping of cache key -> value
 	cacheLock sync.Mutex
 	cache     map[string][]byte
 
+	// Index of orders created, reused if issuance is retried before the order is finalized
+	orders MemoryOrderIndex
+
 	// read lock around getting existing certs
 	// write lock around issuing new certificate
 	certLock sync.RWMutex
@@ -88,7 +92,7 @@ func (m *AutoCert) HTTPHandler(handler http.Handler) http.Handler {
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !strings.HasPrefix(r.URL.Path, "/.well-known/acme-challenge/") {
+		if !strings.HasPrefix(r.URL.Path, HTTP01ChallengePath) {
 			handler.ServeHTTP(w, r)
 			return
 		}
@@ -98,19 +102,18 @@ func (m *AutoCert) HTTPHandler(handler http.Handler) http.Handler {
 			return
 		}
 
-		token := path.Base(r.URL.Path)
-		m.tokensLock.RLock()
-		defer m.tokensLock.RUnlock()
-		keyAuth := m.tokens[token]
-		if len(keyAuth) == 0 {
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-			return
-		}
-
-		_, _ = w.Write(keyAuth)
+		HTTP01Handler(m.tokenStore(), nil).ServeHTTP(w, r)
 	})
 }
 
+// Helper function to get the token store in use.
+func (m *AutoCert) tokenStore() HTTP01TokenStore {
+	if m.TokenStore != nil {
+		return m.TokenStore
+	}
+	return &m.tokens
+}
+
 // GetCertificate implements a tls.Config.GetCertificate hook
 func (m *AutoCert) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
 	name := strings.TrimSuffix(hello.ServerName, ".")
@@ -333,8 +336,9 @@ func (m *AutoCert) issueCert(domainName string) (*tls.Certificate, error) {
 		return nil, fmt.Errorf("autocert: error creating/fetching account: %v", err)
 	}
 
-	// start a new order process
-	order, err := m.client.NewOrderDomains(account, domainName)
+	// reuse a pending or ready order previously created for the domain, or start a new order process
+	// only the local index is checked, walking the account order list on every issuance is too costly
+	order, _, err := m.client.reuseOrder(account, []Identifier{{Type: IdentifierTypeDNS, Value: domainName}}, &m.orders, false)
 	if err != nil {
 		return nil, fmt.Errorf("autocert: error creating new order for domain %s: %v", domainName, err)
 	}
@@ -355,25 +359,20 @@ func (m *AutoCert) issueCert(domainName string) (*tls.Certificate, error) {
 			return nil, fmt.Errorf("autocert: unable to find http-01 challenge for auth %s, Url: %s", auth.Identifier.Value, authURL)
 		}
 
-		m.tokensLock.Lock()
-		if m.tokens == nil {
-			m.tokens = map[string][]byte{}
+		solver := HTTP01StoreSolver{Store: m.tokenStore()}
+		if err := solver.Present(account, auth, chal); err != nil {
+			return nil, fmt.Errorf("autocert: error storing authorization %s challenge token: %v", auth.Identifier.Value, err)
 		}
-		m.tokens[chal.Token] = []byte(chal.KeyAuthorization)
-		m.tokensLock.Unlock()
 
 		if m.PreUpdateChallengeHook != nil {
 			m.PreUpdateChallengeHook(account, chal)
 		}
 
-		chal, err = m.client.UpdateChallenge(account, chal)
+		_, err = m.client.UpdateChallenge(account, chal)
+		_ = solver.CleanUp(account, auth, chal)
 		if err != nil {
 			return nil, fmt.Errorf("autocert: error updating authorization %s challenge (Url: %s) : %v", auth.Identifier.Value, authURL, err)
 		}
-
-		m.tokensLock.Lock()
-		delete(m.tokens, chal.Token)
-		m.tokensLock.Unlock()
 	}
 
 	// generate private key for cert
@@ -390,22 +389,11 @@ func (m *AutoCert) issueCert(domainName string) (*tls.Certificate, error) {
 		Bytes: certKeyEnc,
 	})
 
-	// create the new csr template
-	tpl := &x509.CertificateRequest{
-		SignatureAlgorithm: x509.ECDSAWithSHA256,
-		PublicKeyAlgorithm: x509.ECDSA,
-		PublicKey:          certKey.Public(),
-		Subject:            pkix.Name{CommonName: domainName},
-		DNSNames:           []string{domainName},
-	}
-	csrDer, err := x509.CreateCertificateRequest(rand.Reader, tpl, certKey)
+	// create the new csr for the order identifiers
+	csr, err := NewCSRFromOrder(order, certKey, CSROptCommonName(domainName))
 	if err != nil {
 		return nil, fmt.Errorf("autocert: error creating certificate request for %s: %v", domainName, err)
 	}
-	csr, err := x509.ParseCertificateRequest(csrDer)
-	if err != nil {
-		return nil, fmt.Errorf("autocert: error parsing certificate request for %s: %v", domainName, err)
-	}
 
 	// finalize the order with the acme server given a csr
 	order, err = m.client.FinalizeOrder(account, order, csr)
@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestWhitelistHosts(t *testing.T) {
@@ -36,6 +37,23 @@ func TestAutoCert_HTTPHandler(t *testing.T) {
 	}
 }
 
+func TestAutoCert_HTTPHandler2(t *testing.T) {
+	store := &MemoryTokenStore{}
+	if err := store.PutToken("token1", "token1.thumbprint", time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// token presented by another node sharing the store
+	a := AutoCert{TokenStore: store}
+	handler := a.HTTPHandler(nil)
+	r := httptest.NewRequest("GET", HTTP01ChallengePath+"token1", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Result().StatusCode != http.StatusOK || w.Body.String() != "token1.thumbprint" {
+		t.Fatalf("expected key authorization, got: %d %s", w.Result().StatusCode, w.Body.String())
+	}
+}
+
 func TestAutoCert_GetCertificate(t *testing.T) {
 	tests := []struct {
 		ac     AutoCert
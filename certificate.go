@@ -10,18 +10,9 @@ import (
 )
 
 func (c Client) decodeCertificateChain(body []byte, resp *http.Response, account Account) ([]*x509.Certificate, error) {
-	var certs []*x509.Certificate
-	for {
-		var p *pem.Block
-		p, body = pem.Decode(body)
-		if p == nil {
-			break
-		}
-		cert, err := x509.ParseCertificate(p.Bytes)
-		if err != nil {
-			return certs, fmt.Errorf("acme: error parsing certificate: %v", err)
-		}
-		certs = append(certs, cert)
+	certs, err := decodeCertificates(body)
+	if err != nil {
+		return certs, err
 	}
 
 	up := fetchLink(resp, "up")
@@ -38,6 +29,24 @@ func (c Client) decodeCertificateChain(body []byte, resp *http.Response, account
 	return certs, nil
 }
 
+// Helper function to decode the pem encoded certificates in a response body.
+func decodeCertificates(body []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var p *pem.Block
+		p, body = pem.Decode(body)
+		if p == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(p.Bytes)
+		if err != nil {
+			return certs, fmt.Errorf("acme: error parsing certificate: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
 // FetchCertificates downloads a certificate chain from a url given in an order certificate.
 func (c Client) FetchCertificates(account Account, certificateURL string) ([]*x509.Certificate, error) {
 	resp, body, err := c.postRaw(0, certificateURL, account.URL, account.PrivateKey, "", []int{http.StatusOK})
@@ -47,7 +47,17 @@ func checkUpdatedChallengeStatus(challenge Challenge) (bool, error) {
 
 // UpdateChallenge responds to a challenge to indicate to the server to complete the challenge.
 func (c Client) UpdateChallenge(account Account, challenge Challenge) (Challenge, error) {
-	resp, err := c.post(challenge.URL, account.URL, account.PrivateKey, struct{}{}, &challenge, http.StatusOK)
+	return c.UpdateChallengePayload(account, challenge, struct{}{})
+}
+
+// UpdateChallengePayload responds to a challenge with the provided payload, for challenge types which require a
+// response object other than the empty object, eg device-attest-01 or tkauth-01. Otherwise behaves as UpdateChallenge.
+func (c Client) UpdateChallengePayload(account Account, challenge Challenge, payload interface{}) (Challenge, error) {
+	if payload == nil {
+		return challenge, errors.New("acme: no challenge response payload provided")
+	}
+
+	resp, err := c.post(challenge.URL, account.URL, account.PrivateKey, payload, &challenge, http.StatusOK)
 	if err != nil {
 		return challenge, err
 	}
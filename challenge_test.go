@@ -45,6 +45,32 @@ func TestClient_UpdateChallenge(t *testing.T) {
 	}
 }
 
+func TestClient_UpdateChallengePayload(t *testing.T) {
+	if _, err := testClient.UpdateChallengePayload(Account{}, Challenge{}, nil); err == nil {
+		t.Fatal("expected error with no payload, got none")
+	}
+
+	account, order := makeOrder(t)
+	auth, err := testClient.FetchAuthorization(account, order.Authorizations[0])
+	if err != nil {
+		t.Fatalf("unexpected error fetching authorization: %v", err)
+	}
+
+	chal := auth.ChallengeMap[ChallengeTypeHTTP01]
+
+	preChallenge(account, auth, chal)
+	defer postChallenge(account, auth, chal)
+
+	updatedChal, err := testClient.UpdateChallengePayload(account, chal, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if updatedChal.Status != "valid" {
+		t.Fatalf("expected valid challenge, got: %s", updatedChal.Status)
+	}
+}
+
 func TestClient_FetchChallenge(t *testing.T) {
 	account, order := makeOrder(t)
 	auth, err := testClient.FetchAuthorization(account, order.Authorizations[0])
@@ -0,0 +1,180 @@
+package acme
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+var (
+	// oidTLSFeature is the TLS feature certificate extension.
+	// See https://tools.ietf.org/html/rfc7633#section-6
+	oidTLSFeature = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+
+	// tlsFeatureStatusRequest is the DER encoded TLS feature extension value containing only status_request (5),
+	// ie OCSP Must-Staple.
+	tlsFeatureStatusRequest = []byte{0x30, 0x03, 0x02, 0x01, 0x05}
+)
+
+// NewCSR creates a certificate signing request for a list of identifiers, signed by the certificate private key.
+// DNS, ip and email identifiers are added as subject alternative names, and TNAuthList identifiers as the TNAuthList
+// extension. The signature algorithm is chosen according to the private key type unless provided as an option.
+func NewCSR(identifiers []Identifier, key crypto.Signer, options ...CSROptionFunc) (*x509.CertificateRequest, error) {
+	if len(identifiers) == 0 {
+		return nil, errors.New("acme: no identifiers provided")
+	}
+	if key == nil {
+		return nil, errors.New("acme: no private key provided")
+	}
+
+	tpl := &x509.CertificateRequest{
+		SignatureAlgorithm: csrSignatureAlgorithm(key.Public()),
+	}
+	for _, id := range identifiers {
+		switch id.Type {
+		case IdentifierTypeDNS:
+			if strings.Contains(strings.TrimPrefix(id.Value, "*."), "*") {
+				return nil, fmt.Errorf("acme: invalid wildcard dns identifier: %q", id.Value)
+			}
+			tpl.DNSNames = append(tpl.DNSNames, id.Value)
+		case IdentifierTypeIP:
+			ip := net.ParseIP(id.Value)
+			if ip == nil {
+				return nil, fmt.Errorf("acme: invalid ip identifier: %q", id.Value)
+			}
+			tpl.IPAddresses = append(tpl.IPAddresses, ip)
+		case IdentifierTypeEmail:
+			tpl.EmailAddresses = append(tpl.EmailAddresses, id.Value)
+		case IdentifierTypeTNAuthList:
+			der, err := base64.StdEncoding.DecodeString(id.Value)
+			if err != nil {
+				return nil, fmt.Errorf("acme: invalid TNAuthList identifier: %v", err)
+			}
+			tpl.ExtraExtensions = append(tpl.ExtraExtensions, pkix.Extension{Id: oidTNAuthList, Value: der})
+		default:
+			return nil, fmt.Errorf("acme: unsupported identifier type for csr: %q", id.Type)
+		}
+	}
+
+	for _, opt := range options {
+		if err := opt(tpl); err != nil {
+			return nil, fmt.Errorf("acme: error setting csr option: %v", err)
+		}
+	}
+
+	csrDer, err := x509.CreateCertificateRequest(rand.Reader, tpl, key)
+	if err != nil {
+		return nil, fmt.Errorf("acme: error creating certificate request: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(csrDer)
+	if err != nil {
+		return nil, fmt.Errorf("acme: error parsing certificate request: %v", err)
+	}
+
+	if err := checkCSRIdentifiers(identifiers, csr); err != nil {
+		return nil, err
+	}
+
+	return csr, nil
+}
+
+// NewCSRFromOrder creates a certificate signing request for the identifiers in an order.
+// See NewCSR
+func NewCSRFromOrder(order Order, key crypto.Signer, options ...CSROptionFunc) (*x509.CertificateRequest, error) {
+	return NewCSR(order.Identifiers, key, options...)
+}
+
+// CheckCSR checks a certificate signing request contains exactly the identifiers in an order, and that any subject
+// common name is one of the order identifiers. Should be used before FinalizeOrder to avoid the acme server rejecting
+// the order as invalid.
+func CheckCSR(order Order, csr *x509.CertificateRequest) error {
+	if csr == nil {
+		return errors.New("acme: no certificate request provided")
+	}
+	return checkCSRIdentifiers(order.Identifiers, csr)
+}
+
+// Helper function to check a csr against a list of identifiers.
+func checkCSRIdentifiers(identifiers []Identifier, csr *x509.CertificateRequest) error {
+	want := map[Identifier]bool{}
+	for _, id := range identifiers {
+		normalized, err := NormalizeIdentifier(id)
+		if err != nil {
+			return err
+		}
+		want[normalized] = true
+	}
+
+	var have []Identifier
+	for _, name := range csr.DNSNames {
+		have = append(have, Identifier{Type: IdentifierTypeDNS, Value: name})
+	}
+	for _, ip := range csr.IPAddresses {
+		have = append(have, NewIPIdentifier(ip))
+	}
+	for _, email := range csr.EmailAddresses {
+		have = append(have, Identifier{Type: IdentifierTypeEmail, Value: email})
+	}
+	for _, ext := range csr.Extensions {
+		if ext.Id.Equal(oidTNAuthList) {
+			have = append(have, Identifier{Type: IdentifierTypeTNAuthList, Value: base64.StdEncoding.EncodeToString(ext.Value)})
+		}
+	}
+	if len(csr.URIs) > 0 {
+		return fmt.Errorf("acme: csr contains uri subject alternative names: %v", csr.URIs)
+	}
+
+	found := map[Identifier]bool{}
+	for _, id := range have {
+		normalized, err := NormalizeIdentifier(id)
+		if err != nil {
+			return err
+		}
+		if !want[normalized] {
+			return fmt.Errorf("acme: csr %s identifier %q not in order", id.Type, id.Value)
+		}
+		found[normalized] = true
+	}
+	for id := range want {
+		if !found[id] {
+			return fmt.Errorf("acme: order %s identifier %q missing from csr", id.Type, id.Value)
+		}
+	}
+
+	if cn := csr.Subject.CommonName; cn != "" {
+		dnsCN, _ := NormalizeIdentifier(Identifier{Type: IdentifierTypeDNS, Value: cn})
+		if !want[dnsCN] && !want[NewIdentifier(cn)] {
+			return fmt.Errorf("acme: csr common name %q not in order", cn)
+		}
+	}
+
+	return nil
+}
+
+// Helper function to choose a csr signature algorithm for a public key type, leaving the default otherwise.
+func csrSignatureAlgorithm(pub crypto.PublicKey) x509.SignatureAlgorithm {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return x509.SHA256WithRSA
+	case *ecdsa.PublicKey:
+		switch k.Curve.Params().BitSize {
+		case 384:
+			return x509.ECDSAWithSHA384
+		case 521:
+			return x509.ECDSAWithSHA512
+		default:
+			return x509.ECDSAWithSHA256
+		}
+	default:
+		return x509.UnknownSignatureAlgorithm
+	}
+}
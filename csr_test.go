@@ -0,0 +1,124 @@
+package acme
+
+import (
+	"bytes"
+	"crypto/x509"
+	"strings"
+	"testing"
+)
+
+func TestNewCSR(t *testing.T) {
+	key := makePrivateKey(t)
+
+	if _, err := NewCSR(nil, key); err == nil {
+		t.Fatal("expected error with no identifiers, got none")
+	}
+	if _, err := NewCSR([]Identifier{{Type: "dns", Value: "example.com"}}, nil); err == nil {
+		t.Fatal("expected error with no key, got none")
+	}
+	if _, err := NewCSR([]Identifier{{Type: "dns", Value: "www.*.example.com"}}, key); err == nil {
+		t.Fatal("expected error with invalid wildcard, got none")
+	}
+	if _, err := NewCSR([]Identifier{{Type: "dns", Value: "example.com"}}, key, CSROptCommonName(strings.Repeat("a", 65))); err == nil {
+		t.Fatal("expected error with long common name, got none")
+	}
+	if _, err := NewCSR([]Identifier{{Type: "dns", Value: "example.com"}}, key, CSROptCommonName("other.com")); err == nil {
+		t.Fatal("expected error with common name not in identifiers, got none")
+	}
+
+	ids := []Identifier{
+		{Type: "dns", Value: "*.example.com"},
+		{Type: "ip", Value: "2001:db8::1"},
+		{Type: "email", Value: "user@example.com"},
+	}
+	csr, err := NewCSR(ids, key, CSROptMustStaple())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if csr.Subject.CommonName != "" {
+		t.Fatalf("expected no common name, got: %q", csr.Subject.CommonName)
+	}
+	if csr.SignatureAlgorithm != x509.ECDSAWithSHA256 {
+		t.Fatalf("unexpected signature algorithm: %v", csr.SignatureAlgorithm)
+	}
+	if len(csr.DNSNames) != 1 || len(csr.IPAddresses) != 1 || len(csr.EmailAddresses) != 1 {
+		t.Fatalf("unexpected subject alternative names: %v %v %v", csr.DNSNames, csr.IPAddresses, csr.EmailAddresses)
+	}
+	found := false
+	for _, ext := range csr.Extensions {
+		if ext.Id.Equal(oidTLSFeature) && bytes.Equal(ext.Value, tlsFeatureStatusRequest) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected must staple extension, got none")
+	}
+
+	p384, err := generatePrivateKey(KeyTypeECDSAP384)
+	if err != nil {
+		t.Fatalf("unexpected error generating key: %v", err)
+	}
+	csr, err = NewCSR(ids[:1], p384)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if csr.SignatureAlgorithm != x509.ECDSAWithSHA384 {
+		t.Fatalf("unexpected signature algorithm: %v", csr.SignatureAlgorithm)
+	}
+
+	rsaKey, err := generatePrivateKey(KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("unexpected error generating key: %v", err)
+	}
+	csr, err = NewCSR(ids[:1], rsaKey, CSROptSignatureAlgorithm(x509.SHA384WithRSA))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if csr.SignatureAlgorithm != x509.SHA384WithRSA {
+		t.Fatalf("unexpected signature algorithm: %v", csr.SignatureAlgorithm)
+	}
+}
+
+func TestCheckCSR(t *testing.T) {
+	key := makePrivateKey(t)
+	order := Order{Identifiers: []Identifier{{Type: "dns", Value: "Example.com"}, {Type: "ip", Value: "192.0.2.1"}}}
+
+	if err := CheckCSR(order, nil); err == nil {
+		t.Fatal("expected error with no csr, got none")
+	}
+
+	csr, err := NewCSR([]Identifier{{Type: "dns", Value: "example.com"}, {Type: "ip", Value: "::ffff:192.0.2.1"}}, key, CSROptCommonName("example.com"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := CheckCSR(order, csr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	csr, err = NewCSR([]Identifier{{Type: "dns", Value: "example.com"}}, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := CheckCSR(order, csr); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("expected missing identifier error, got: %v", err)
+	}
+
+	csr, err = NewCSR([]Identifier{{Type: "dns", Value: "example.com"}, {Type: "ip", Value: "192.0.2.1"}, {Type: "dns", Value: "other.com"}}, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := CheckCSR(order, csr); err == nil || !strings.Contains(err.Error(), "not in order") {
+		t.Fatalf("expected extra identifier error, got: %v", err)
+	}
+}
+
+func TestNewCSRFromOrder(t *testing.T) {
+	order := Order{Identifiers: []Identifier{{Type: "dns", Value: "example.com"}}}
+	csr, err := NewCSRFromOrder(order, makePrivateKey(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := CheckCSR(order, csr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Special values used in a CSR template.
+// See https://tools.ietf.org/html/rfc9115#section-4
+const (
+	// CSRTemplateMandatory indicates the field must be present in the CSR, with any value.
+	CSRTemplateMandatory = "*"
+
+	// CSRTemplateOptional indicates the field may be present in the CSR, with any value.
+	CSRTemplateOptional = "**"
+)
+
+// Delegation is a delegation configuration provided by an identifier owner, used by a delegee (eg a CDN) to request
+// certificates for the identifier owner's names.
+// See https://tools.ietf.org/html/rfc9115#section-2.3.1
+type Delegation struct {
+	CSRTemplate CSRTemplate       `json:"csr-template"`
+	CNAMEMap    map[string]string `json:"cname-map,omitempty"`
+
+	// URL of the delegation object.
+	URL string `json:"-"`
+}
+
+// CSRTemplate constrains the certificate signing request submitted when finalizing a delegated order.
+// See https://tools.ietf.org/html/rfc9115#section-4
+type CSRTemplate struct {
+	KeyTypes   []CSRTemplateKeyType  `json:"keyTypes"`
+	Subject    map[string]string     `json:"subject,omitempty"`
+	Extensions CSRTemplateExtensions `json:"extensions"`
+}
+
+// CSRTemplateKeyType is one of the key and signature types allowed by a CSR template.
+type CSRTemplateKeyType struct {
+	// PublicKeyType is either "rsaEncryption" or "id-ecPublicKey".
+	PublicKeyType string `json:"PublicKeyType"`
+
+	// NamedCurve is the curve of an "id-ecPublicKey" key, eg "secp256r1".
+	NamedCurve string `json:"namedCurve,omitempty"`
+
+	// PublicKeyLength is the size in bits of an "rsaEncryption" key.
+	PublicKeyLength int `json:"PublicKeyLength,omitempty"`
+
+	// SignatureType is the CSR signature algorithm, eg "ecdsa-with-SHA256" or "sha256WithRSAEncryption".
+	SignatureType string `json:"SignatureType"`
+}
+
+// CSRTemplateExtensions are the certificate extensions allowed by a CSR template.
+type CSRTemplateExtensions struct {
+	SubjectAltName struct {
+		DNS   []string `json:"DNS,omitempty"`
+		Email []string `json:"Email,omitempty"`
+		URI   []string `json:"URI,omitempty"`
+	} `json:"subjectAltName"`
+	KeyUsage         []string `json:"keyUsage,omitempty"`
+	ExtendedKeyUsage []string `json:"extendedKeyUsage,omitempty"`
+}
+
+// FetchDelegationList fetches the list of delegations available to an account, provided in the account Delegations
+// field.
+// See https://tools.ietf.org/html/rfc9115#section-2.3.1
+func (c Client) FetchDelegationList(account Account) (DelegationList, error) {
+	delegationList := DelegationList{}
+
+	if account.Delegations == "" {
+		return delegationList, errors.New("no delegation list for account")
+	}
+
+	resp, err := c.post(account.Delegations, account.URL, account.PrivateKey, "", &delegationList, http.StatusOK)
+	if err != nil {
+		return delegationList, err
+	}
+
+	delegationList.Next = fetchLink(resp, "next")
+
+	return delegationList, nil
+}
+
+// FetchDelegation fetches a delegation configuration given its url.
+func (c Client) FetchDelegation(account Account, delegationURL string) (Delegation, error) {
+	delegation := Delegation{}
+
+	if _, err := c.post(delegationURL, account.URL, account.PrivateKey, "", &delegation, http.StatusOK); err != nil {
+		return delegation, err
+	}
+
+	delegation.URL = delegationURL
+
+	return delegation, nil
+}
+
+// NewDelegatedOrder initiates a new order using a delegation configuration. If no identifiers are provided, the dns
+// names in the delegation CSR template are used. The CSR submitted when finalizing the order must conform to the
+// delegation CSR template, see CheckCSRTemplate.
+// See https://tools.ietf.org/html/rfc9115#section-2.3.2
+func (c Client) NewDelegatedOrder(account Account, delegation Delegation, identifiers []Identifier) (Order, error) {
+	if delegation.URL == "" {
+		return Order{}, errors.New("acme: no delegation url provided")
+	}
+
+	if len(identifiers) == 0 {
+		for _, name := range delegation.CSRTemplate.Extensions.SubjectAltName.DNS {
+			if name == CSRTemplateMandatory || name == CSRTemplateOptional {
+				continue
+			}
+			identifiers = append(identifiers, Identifier{Type: IdentifierTypeDNS, Value: name})
+		}
+	}
+	if len(identifiers) == 0 {
+		return Order{}, errors.New("acme: no identifiers provided or present in delegation csr template")
+	}
+
+	return c.ReplacementOrderExtension(account, nil, identifiers, OrderExtension{Delegation: delegation.URL})
+}
+
+var (
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+
+	// key usage names, in bit string order
+	csrTemplateKeyUsages = []string{
+		"digitalSignature", "nonRepudiation", "keyEncipherment", "dataEncipherment", "keyAgreement", "keyCertSign",
+		"cRLSign", "encipherOnly", "decipherOnly",
+	}
+
+	csrTemplateExtKeyUsages = map[string]asn1.ObjectIdentifier{
+		"serverAuth":      {1, 3, 6, 1, 5, 5, 7, 3, 1},
+		"clientAuth":      {1, 3, 6, 1, 5, 5, 7, 3, 2},
+		"codeSigning":     {1, 3, 6, 1, 5, 5, 7, 3, 3},
+		"emailProtection": {1, 3, 6, 1, 5, 5, 7, 3, 4},
+		"timeStamping":    {1, 3, 6, 1, 5, 5, 7, 3, 8},
+		"OCSPSigning":     {1, 3, 6, 1, 5, 5, 7, 3, 9},
+	}
+
+	csrTemplateCurves = map[string]string{
+		"secp256r1": "P-256",
+		"secp384r1": "P-384",
+		"secp521r1": "P-521",
+	}
+
+	csrTemplateSignatures = map[string]x509.SignatureAlgorithm{
+		"sha256WithRSAEncryption": x509.SHA256WithRSA,
+		"sha384WithRSAEncryption": x509.SHA384WithRSA,
+		"sha512WithRSAEncryption": x509.SHA512WithRSA,
+		"ecdsa-with-SHA256":       x509.ECDSAWithSHA256,
+		"ecdsa-with-SHA384":       x509.ECDSAWithSHA384,
+		"ecdsa-with-SHA512":       x509.ECDSAWithSHA512,
+	}
+)
+
+// CheckCSRTemplate checks a certificate signing request conforms to a delegation CSR template before finalizing a
+// delegated order. The CSR key and signature must match one of the template key types, and any subject fields, subject
+// alternative names, key usages and extended key usages present in the CSR must be allowed by the template. Template
+// values of CSRTemplateMandatory must be present in the CSR, and literal subject alternative names must be present.
+func CheckCSRTemplate(csr *x509.CertificateRequest, tpl CSRTemplate) error {
+	if csr == nil {
+		return errors.New("acme: no certificate request provided")
+	}
+
+	if err := checkCSRTemplateKeyTypes(csr, tpl.KeyTypes); err != nil {
+		return err
+	}
+
+	subject := map[string][]string{
+		"commonName":         nil,
+		"country":            csr.Subject.Country,
+		"stateOrProvince":    csr.Subject.Province,
+		"locality":           csr.Subject.Locality,
+		"organization":       csr.Subject.Organization,
+		"organizationalUnit": csr.Subject.OrganizationalUnit,
+	}
+	if csr.Subject.CommonName != "" {
+		subject["commonName"] = []string{csr.Subject.CommonName}
+	}
+	for field, want := range tpl.Subject {
+		if _, ok := subject[field]; !ok && want != CSRTemplateOptional {
+			return fmt.Errorf("acme: unsupported csr template subject field: %q", field)
+		}
+	}
+	for field, have := range subject {
+		want, ok := tpl.Subject[field]
+		if err := checkCSRTemplateSubject(field, want, ok, have); err != nil {
+			return err
+		}
+	}
+
+	san := tpl.Extensions.SubjectAltName
+	if len(csr.IPAddresses) > 0 {
+		return errors.New("acme: csr template does not allow ip address subject alternative names")
+	}
+	var uris []string
+	for _, u := range csr.URIs {
+		uris = append(uris, u.String())
+	}
+	if err := checkCSRTemplateValues("dns name", san.DNS, csr.DNSNames); err != nil {
+		return err
+	}
+	if err := checkCSRTemplateValues("email address", san.Email, csr.EmailAddresses); err != nil {
+		return err
+	}
+	if err := checkCSRTemplateValues("uri", san.URI, uris); err != nil {
+		return err
+	}
+
+	// copy so appending can't write into the csr extensions backing array
+	exts := make([]pkix.Extension, 0, len(csr.Extensions)+len(csr.ExtraExtensions))
+	exts = append(append(exts, csr.Extensions...), csr.ExtraExtensions...)
+	for _, ext := range exts {
+		switch {
+		case ext.Id.Equal(oidExtensionKeyUsage):
+			var bits asn1.BitString
+			if _, err := asn1.Unmarshal(ext.Value, &bits); err != nil {
+				return fmt.Errorf("acme: error parsing csr key usage: %v", err)
+			}
+			for i, name := range csrTemplateKeyUsages {
+				if bits.At(i) != 0 && !containsString(tpl.Extensions.KeyUsage, name) {
+					return fmt.Errorf("acme: csr key usage %q not allowed by template", name)
+				}
+			}
+
+		case ext.Id.Equal(oidExtensionExtendedKeyUsage):
+			var oids []asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(ext.Value, &oids); err != nil {
+				return fmt.Errorf("acme: error parsing csr extended key usage: %v", err)
+			}
+			for _, oid := range oids {
+				if !csrTemplateAllowsExtKeyUsage(tpl.Extensions.ExtendedKeyUsage, oid) {
+					return fmt.Errorf("acme: csr extended key usage %v not allowed by template", oid)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Helper function to check the csr key and signature match one of the template key types.
+func checkCSRTemplateKeyTypes(csr *x509.CertificateRequest, keyTypes []CSRTemplateKeyType) error {
+	for _, kt := range keyTypes {
+		if sig, ok := csrTemplateSignatures[kt.SignatureType]; kt.SignatureType != "" && (!ok || sig != csr.SignatureAlgorithm) {
+			continue
+		}
+		switch pub := csr.PublicKey.(type) {
+		case *rsa.PublicKey:
+			if kt.PublicKeyType == "rsaEncryption" && (kt.PublicKeyLength == 0 || kt.PublicKeyLength == pub.N.BitLen()) {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			if kt.PublicKeyType == "id-ecPublicKey" && (kt.NamedCurve == "" || csrTemplateCurves[kt.NamedCurve] == pub.Curve.Params().Name) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("acme: csr key type %v with signature %v not allowed by template", csr.PublicKeyAlgorithm, csr.SignatureAlgorithm)
+}
+
+// Helper function to check a single csr subject field against the template.
+func checkCSRTemplateSubject(field, want string, inTemplate bool, have []string) error {
+	if len(have) > 1 {
+		return fmt.Errorf("acme: csr subject field %q has multiple values", field)
+	}
+	if !inTemplate {
+		if len(have) > 0 {
+			return fmt.Errorf("acme: csr subject field %q not allowed by template", field)
+		}
+		return nil
+	}
+	switch want {
+	case CSRTemplateOptional:
+		return nil
+	case CSRTemplateMandatory:
+		if len(have) == 0 {
+			return fmt.Errorf("acme: csr subject field %q required by template", field)
+		}
+		return nil
+	default:
+		if len(have) == 0 || have[0] != want {
+			return fmt.Errorf("acme: csr subject field %q must be %q", field, want)
+		}
+		return nil
+	}
+}
+
+// Helper function to check csr values are allowed by, and literal values are present from, a list of template values.
+func checkCSRTemplateValues(name string, want, have []string) error {
+	wildcard := false
+	for _, w := range want {
+		if w == CSRTemplateMandatory || w == CSRTemplateOptional {
+			wildcard = true
+			continue
+		}
+		if !containsString(have, w) {
+			return fmt.Errorf("acme: csr missing %s %q required by template", name, w)
+		}
+	}
+	for _, h := range have {
+		if !wildcard && !containsString(want, h) {
+			return fmt.Errorf("acme: csr %s %q not allowed by template", name, h)
+		}
+	}
+	if containsString(want, CSRTemplateMandatory) && len(have) == 0 {
+		return fmt.Errorf("acme: csr %s required by template", name)
+	}
+	return nil
+}
+
+// Helper function to check whether an extended key usage oid is allowed by name or dotted oid in the template.
+func csrTemplateAllowsExtKeyUsage(allowed []string, oid asn1.ObjectIdentifier) bool {
+	for _, a := range allowed {
+		if known, ok := csrTemplateExtKeyUsages[a]; ok && known.Equal(oid) {
+			return true
+		}
+		if a == oid.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to check whether a list of strings contains a value, compared case-insensitively.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
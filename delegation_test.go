@@ -0,0 +1,184 @@
+package acme
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testDelegation = `{
+  "csr-template": {
+    "keyTypes": [
+      {"PublicKeyType": "id-ecPublicKey", "namedCurve": "secp256r1", "SignatureType": "ecdsa-with-SHA256"}
+    ],
+    "subject": {"country": "CA", "organization": "**", "commonName": "*"},
+    "extensions": {
+      "subjectAltName": {"DNS": ["abc.ndc.dno.example"]},
+      "keyUsage": ["digitalSignature"],
+      "extendedKeyUsage": ["serverAuth"]
+    }
+  },
+  "cname-map": {
+    "_acme-challenge.abc.ndc.dno.example": "_acme-challenge.abc.ndc.dno.example.cdn.example"
+  }
+}`
+
+func TestCheckCSRTemplate(t *testing.T) {
+	var delegation Delegation
+	if err := json.Unmarshal([]byte(testDelegation), &delegation); err != nil {
+		t.Fatalf("unexpected error parsing delegation: %v", err)
+	}
+	if len(delegation.CNAMEMap) != 1 {
+		t.Fatalf("unexpected cname map: %v", delegation.CNAMEMap)
+	}
+
+	key := makePrivateKey(t)
+	usage := func(oids ...asn1.ObjectIdentifier) pkix.Extension {
+		b, _ := asn1.Marshal(oids)
+		return pkix.Extension{Id: oidExtensionExtendedKeyUsage, Value: b}
+	}
+	keyUsage := func(bits ...int) pkix.Extension {
+		b := make([]byte, 2)
+		for _, i := range bits {
+			b[i/8] |= 0x80 >> uint(i%8)
+		}
+		v, _ := asn1.Marshal(asn1.BitString{Bytes: b, BitLength: 9})
+		return pkix.Extension{Id: oidExtensionKeyUsage, Value: v}
+	}
+
+	tests := []struct {
+		name        string
+		tpl         x509.CertificateRequest
+		errorString string
+	}{
+		{
+			name: "conforming",
+			tpl: x509.CertificateRequest{
+				Subject:         pkix.Name{CommonName: "abc.ndc.dno.example", Country: []string{"CA"}, Organization: []string{"Example"}},
+				DNSNames:        []string{"abc.ndc.dno.example"},
+				ExtraExtensions: []pkix.Extension{keyUsage(0), usage(csrTemplateExtKeyUsages["serverAuth"])},
+			},
+		},
+		{
+			name: "missing mandatory common name",
+			tpl: x509.CertificateRequest{
+				Subject:  pkix.Name{Country: []string{"CA"}},
+				DNSNames: []string{"abc.ndc.dno.example"},
+			},
+			errorString: "required by template",
+		},
+		{
+			name: "wrong country",
+			tpl: x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "abc.ndc.dno.example", Country: []string{"US"}},
+				DNSNames: []string{"abc.ndc.dno.example"},
+			},
+			errorString: "must be",
+		},
+		{
+			name: "subject field not in template",
+			tpl: x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "abc.ndc.dno.example", Country: []string{"CA"}, Locality: []string{"Ottawa"}},
+				DNSNames: []string{"abc.ndc.dno.example"},
+			},
+			errorString: "not allowed",
+		},
+		{
+			name: "extra dns name",
+			tpl: x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "abc.ndc.dno.example", Country: []string{"CA"}},
+				DNSNames: []string{"abc.ndc.dno.example", "other.example"},
+			},
+			errorString: "not allowed",
+		},
+		{
+			name: "missing dns name",
+			tpl: x509.CertificateRequest{
+				Subject: pkix.Name{CommonName: "abc.ndc.dno.example", Country: []string{"CA"}},
+			},
+			errorString: "missing dns name",
+		},
+		{
+			name: "key usage not allowed",
+			tpl: x509.CertificateRequest{
+				Subject:         pkix.Name{CommonName: "abc.ndc.dno.example", Country: []string{"CA"}},
+				DNSNames:        []string{"abc.ndc.dno.example"},
+				ExtraExtensions: []pkix.Extension{keyUsage(0, 8)},
+			},
+			errorString: "decipherOnly",
+		},
+		{
+			name: "extended key usage not allowed",
+			tpl: x509.CertificateRequest{
+				Subject:         pkix.Name{CommonName: "abc.ndc.dno.example", Country: []string{"CA"}},
+				DNSNames:        []string{"abc.ndc.dno.example"},
+				ExtraExtensions: []pkix.Extension{usage(csrTemplateExtKeyUsages["clientAuth"])},
+			},
+			errorString: "extended key usage",
+		},
+	}
+
+	for _, ct := range tests {
+		csrDer, err := x509.CreateCertificateRequest(rand.Reader, &ct.tpl, key)
+		if err != nil {
+			t.Fatalf("%s: error creating csr: %v", ct.name, err)
+		}
+		csr, err := x509.ParseCertificateRequest(csrDer)
+		if err != nil {
+			t.Fatalf("%s: error parsing csr: %v", ct.name, err)
+		}
+
+		// spare capacity in the caller's extensions must not be written to
+		spare := pkix.Extension{Id: asn1.ObjectIdentifier{1, 2, 3}}
+		exts := append(make([]pkix.Extension, 0, len(csr.Extensions)+1), csr.Extensions...)
+		csr.Extensions = append(exts, spare)[:len(exts)]
+		csr.ExtraExtensions = []pkix.Extension{usage()}
+
+		err = CheckCSRTemplate(csr, delegation.CSRTemplate)
+		if got := csr.Extensions[:cap(csr.Extensions)][len(csr.Extensions)]; !got.Id.Equal(spare.Id) {
+			t.Errorf("%s: csr extensions backing array modified: %v", ct.name, got.Id)
+		}
+		if ct.errorString == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", ct.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), ct.errorString) {
+			t.Errorf("%s: expected error containing %q, got: %v", ct.name, ct.errorString, err)
+		}
+	}
+
+	rsaKey, err := generatePrivateKey(KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("unexpected error generating key: %v", err)
+	}
+	csr, err := createCSR([]Identifier{{Type: "dns", Value: "abc.ndc.dno.example"}}, rsaKey)
+	if err != nil {
+		t.Fatalf("unexpected error creating csr: %v", err)
+	}
+	if err := CheckCSRTemplate(csr, delegation.CSRTemplate); err == nil || !strings.Contains(err.Error(), "key type") {
+		t.Fatalf("expected key type error, got: %v", err)
+	}
+}
+
+func TestClient_NewDelegatedOrder(t *testing.T) {
+	if _, err := testClient.NewDelegatedOrder(Account{}, Delegation{}, nil); err == nil {
+		t.Fatal("expected error with no delegation url, got none")
+	}
+	delegation := Delegation{URL: "https://example.com/delegation/1"}
+	delegation.CSRTemplate.Extensions.SubjectAltName.DNS = []string{CSRTemplateMandatory}
+	if _, err := testClient.NewDelegatedOrder(Account{}, delegation, nil); err == nil {
+		t.Fatal("expected error with no identifiers, got none")
+	}
+}
+
+func TestClient_FetchDelegationList(t *testing.T) {
+	if _, err := testClient.FetchDelegationList(Account{}); err == nil {
+		t.Fatal("expected error with no delegation list, got none")
+	}
+}
@@ -0,0 +1,139 @@
+package acme
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// DeviceAttestation is an attestation statement produced by a device for the device-attest-01 challenge.
+// See https://datatracker.ietf.org/doc/html/draft-ietf-acme-device-attest#section-5
+type DeviceAttestation struct {
+	// Format is the WebAuthn attestation statement format identifier, eg "apple", "tpm" or "android-key".
+	Format string
+
+	// Statement is the CBOR encoded attestation statement (attStmt) map, as specified by the statement format.
+	Statement []byte
+}
+
+// DeviceAttester produces an attestation statement including the provided nonce, eg from a TPM or secure enclave.
+type DeviceAttester func(nonce []byte) (DeviceAttestation, error)
+
+// DeviceAttest01Nonce returns the nonce an attestation statement must include to be bound to the key authorization
+// of a device-attest-01 challenge, the SHA-256 digest of the key authorization.
+func DeviceAttest01Nonce(keyAuth string) []byte {
+	h := sha256.Sum256([]byte(keyAuth))
+	return h[:]
+}
+
+// EncodeDeviceAttest01AttObj encodes an attestation statement as a CBOR WebAuthn attestation object, with an empty
+// authData as the field is unused by the device-attest-01 challenge.
+func EncodeDeviceAttest01AttObj(attestation DeviceAttestation) ([]byte, error) {
+	if attestation.Format == "" {
+		return nil, errors.New("acme: no attestation statement format provided")
+	}
+	// a cbor map has major type 5, in the top 3 bits of the initial byte
+	if len(attestation.Statement) == 0 || attestation.Statement[0]>>5 != 5 {
+		return nil, errors.New("acme: attestation statement must be a cbor encoded map")
+	}
+
+	// keys in canonical cbor order, shortest first
+	attObj := cborHead(5, 3)
+	attObj = append(attObj, cborText("fmt")...)
+	attObj = append(attObj, cborText(attestation.Format)...)
+	attObj = append(attObj, cborText("attStmt")...)
+	attObj = append(attObj, attestation.Statement...)
+	attObj = append(attObj, cborText("authData")...)
+	attObj = append(attObj, cborHead(2, 0)...)
+
+	return attObj, nil
+}
+
+// DeviceAttest01Payload builds the device-attest-01 challenge response payload, calling the attester with the nonce
+// bound to the key authorization and encoding the resulting attestation object.
+func DeviceAttest01Payload(keyAuth string, attester DeviceAttester) (interface{}, error) {
+	if attester == nil {
+		return nil, errors.New("acme: no device attester provided")
+	}
+
+	attestation, err := attester(DeviceAttest01Nonce(keyAuth))
+	if err != nil {
+		return nil, fmt.Errorf("acme: error attesting device: %v", err)
+	}
+
+	attObj, err := EncodeDeviceAttest01AttObj(attestation)
+	if err != nil {
+		return nil, err
+	}
+
+	return struct {
+		AttObj string `json:"attObj"`
+	}{
+		AttObj: base64.RawURLEncoding.EncodeToString(attObj),
+	}, nil
+}
+
+// UpdateDeviceAttestChallenge responds to a device-attest-01 challenge with an attestation object bound to the
+// challenge key authorization, and waits for the challenge to be validated as per UpdateChallenge.
+func (c Client) UpdateDeviceAttestChallenge(account Account, challenge Challenge, attester DeviceAttester) (Challenge, error) {
+	payload, err := DeviceAttest01Payload(challenge.KeyAuthorization, attester)
+	if err != nil {
+		return challenge, err
+	}
+	return c.UpdateChallengePayload(account, challenge, payload)
+}
+
+// DeviceAttest01Solver is a Solver for device-attest-01 challenges, responding with an attestation object bound to
+// the challenge key authorization.
+type DeviceAttest01Solver struct {
+	Attester DeviceAttester
+}
+
+// Present does nothing, the attestation is provided in the challenge response, see Payload.
+func (s DeviceAttest01Solver) Present(account Account, auth Authorization, chal Challenge) error {
+	if s.Attester == nil {
+		return errors.New("acme: no device attester")
+	}
+	return nil
+}
+
+// CleanUp does nothing.
+func (s DeviceAttest01Solver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	return nil
+}
+
+// Payload returns the challenge response containing the attestation object, implementing SolverPayload.
+func (s DeviceAttest01Solver) Payload(account Account, auth Authorization, chal Challenge) (interface{}, error) {
+	return DeviceAttest01Payload(chal.KeyAuthorization, s.Attester)
+}
+
+// Helper function to encode a cbor data item head of the given major type and argument.
+// See https://tools.ietf.org/html/rfc8949#section-3
+func cborHead(major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return []byte{major | byte(n)}
+	case n <= 0xff:
+		return []byte{major | 24, byte(n)}
+	case n <= 0xffff:
+		b := []byte{major | 25, 0, 0}
+		binary.BigEndian.PutUint16(b[1:], uint16(n))
+		return b
+	case n <= 0xffffffff:
+		b := []byte{major | 26, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(b[1:], uint32(n))
+		return b
+	default:
+		b := []byte{major | 27, 0, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint64(b[1:], n)
+		return b
+	}
+}
+
+// Helper function to encode a cbor text string.
+func cborText(s string) []byte {
+	return append(cborHead(3, uint64(len(s))), s...)
+}
@@ -0,0 +1,118 @@
+package acme
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func Test_cborHead(t *testing.T) {
+	tests := []struct {
+		major    byte
+		n        uint64
+		expected []byte
+	}{
+		{0, 0, []byte{0x00}},
+		{3, 23, []byte{0x77}},
+		{3, 24, []byte{0x78, 0x18}},
+		{2, 0x100, []byte{0x59, 0x01, 0x00}},
+		{5, 0x10000, []byte{0xba, 0x00, 0x01, 0x00, 0x00}},
+		{0, 0x100000000, []byte{0x1b, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}},
+	}
+	for _, ct := range tests {
+		if b := cborHead(ct.major, ct.n); !bytes.Equal(b, ct.expected) {
+			t.Errorf("major %d n %d: expected %x, got %x", ct.major, ct.n, ct.expected, b)
+		}
+	}
+}
+
+func TestEncodeDeviceAttest01AttObj(t *testing.T) {
+	if _, err := EncodeDeviceAttest01AttObj(DeviceAttestation{Statement: []byte{0xa0}}); err == nil {
+		t.Fatal("expected error with no format, got none")
+	}
+	if _, err := EncodeDeviceAttest01AttObj(DeviceAttestation{Format: "apple", Statement: []byte{0x80}}); err == nil {
+		t.Fatal("expected error with non-map statement, got none")
+	}
+
+	attObj, err := EncodeDeviceAttest01AttObj(DeviceAttestation{Format: "apple", Statement: []byte{0xa0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// {"fmt": "apple", "attStmt": {}, "authData": h''}
+	expected := []byte{0xa3,
+		0x63, 'f', 'm', 't', 0x65, 'a', 'p', 'p', 'l', 'e',
+		0x67, 'a', 't', 't', 'S', 't', 'm', 't', 0xa0,
+		0x68, 'a', 'u', 't', 'h', 'D', 'a', 't', 'a', 0x40,
+	}
+	if !bytes.Equal(attObj, expected) {
+		t.Fatalf("expected %x, got %x", expected, attObj)
+	}
+}
+
+func TestDeviceAttest01Payload(t *testing.T) {
+	keyAuth := "token.thumbprint"
+
+	if _, err := DeviceAttest01Payload(keyAuth, nil); err == nil {
+		t.Fatal("expected error with no attester, got none")
+	}
+
+	_, err := DeviceAttest01Payload(keyAuth, func(nonce []byte) (DeviceAttestation, error) {
+		return DeviceAttestation{}, errors.New("ALWAYS ERRORS")
+	})
+	if err == nil {
+		t.Fatal("expected attester error, got none")
+	}
+
+	var gotNonce []byte
+	payload, err := DeviceAttest01Payload(keyAuth, func(nonce []byte) (DeviceAttestation, error) {
+		gotNonce = nonce
+		return DeviceAttestation{Format: "apple", Statement: []byte{0xa0}}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := sha256.Sum256([]byte(keyAuth))
+	if !bytes.Equal(gotNonce, h[:]) {
+		t.Fatalf("expected nonce bound to key authorization, got: %x", gotNonce)
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling payload: %v", err)
+	}
+	var decoded struct {
+		AttObj string `json:"attObj"`
+	}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error unmarshalling payload: %v", err)
+	}
+	attObj, err := base64.RawURLEncoding.DecodeString(decoded.AttObj)
+	if err != nil {
+		t.Fatalf("unexpected error decoding attObj: %v", err)
+	}
+	if len(attObj) == 0 || attObj[0] != 0xa3 {
+		t.Fatalf("unexpected attObj: %x", attObj)
+	}
+}
+
+func TestDeviceAttest01Solver_Payload(t *testing.T) {
+	if err := (DeviceAttest01Solver{}).Present(Account{}, Authorization{}, Challenge{}); err == nil {
+		t.Fatal("expected error with no attester, got none")
+	}
+
+	var gotNonce []byte
+	solver := DeviceAttest01Solver{Attester: func(nonce []byte) (DeviceAttestation, error) {
+		gotNonce = nonce
+		return DeviceAttestation{Format: "apple", Statement: []byte{0xa0}}, nil
+	}}
+	if _, err := solver.Payload(Account{}, Authorization{}, Challenge{KeyAuthorization: "token.thumbprint"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := sha256.Sum256([]byte("token.thumbprint"))
+	if !bytes.Equal(gotNonce, h[:]) {
+		t.Fatalf("expected nonce bound to challenge key authorization, got: %x", gotNonce)
+	}
+}
@@ -0,0 +1,80 @@
+package acme
+
+import (
+	"errors"
+	"strings"
+)
+
+// DNSProvider publishes and removes the TXT records used to fulfil dns based challenges.
+type DNSProvider interface {
+	// Present creates a TXT record with the value at the fully qualified domain name.
+	Present(fqdn, value string) error
+
+	// CleanUp removes the TXT record with the value at the fully qualified domain name, leaving any other values.
+	CleanUp(fqdn, value string) error
+}
+
+// DNS01RecordName returns the fully qualified domain name of the TXT record used for a dns-01 challenge for a dns
+// identifier. Any wildcard prefix is removed, as wildcard authorizations are validated at the base domain.
+// See https://tools.ietf.org/html/rfc8555#section-8.4
+func DNS01RecordName(domain string) string {
+	domain = strings.TrimPrefix(domain, "*.")
+	return "_acme-challenge." + strings.TrimSuffix(domain, ".") + "."
+}
+
+// DNS01Solver is a Solver for dns-01 challenges which publishes the TXT record using a DNSProvider.
+type DNS01Solver struct {
+	Provider DNSProvider
+
+	// Propagation, if set, is used to wait for the TXT record to be published by every authoritative nameserver
+	// before the challenge is updated.
+	Propagation *DNSPropagationChecker
+}
+
+// Present publishes the TXT record for the challenge.
+func (s DNS01Solver) Present(account Account, auth Authorization, chal Challenge) error {
+	return s.record().present(DNS01RecordName(auth.Identifier.Value), EncodeDNS01KeyAuthorization(chal.KeyAuthorization))
+}
+
+// CleanUp removes the TXT record for the challenge.
+func (s DNS01Solver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	return s.record().cleanUp(DNS01RecordName(auth.Identifier.Value), EncodeDNS01KeyAuthorization(chal.KeyAuthorization))
+}
+
+// Wait waits for the TXT record to propagate if a propagation checker is set, implementing SolverWaiter.
+func (s DNS01Solver) Wait(account Account, auth Authorization, chal Challenge) error {
+	return s.record().wait(DNS01RecordName(auth.Identifier.Value), EncodeDNS01KeyAuthorization(chal.KeyAuthorization))
+}
+
+func (s DNS01Solver) record() dnsRecordSolver {
+	return dnsRecordSolver{provider: s.Provider, propagation: s.Propagation}
+}
+
+// dnsRecordSolver publishes, removes and waits for the TXT record of a dns based challenge, shared by the dns
+// challenge solvers which only differ in the record name.
+type dnsRecordSolver struct {
+	provider    DNSProvider
+	propagation *DNSPropagationChecker
+}
+
+func (s dnsRecordSolver) present(fqdn, value string) error {
+	if s.provider == nil {
+		return errors.New("acme: no dns provider")
+	}
+	return s.provider.Present(fqdn, value)
+}
+
+func (s dnsRecordSolver) cleanUp(fqdn, value string) error {
+	if s.provider == nil {
+		return errors.New("acme: no dns provider")
+	}
+	return s.provider.CleanUp(fqdn, value)
+}
+
+// Helper function to wait for the record to propagate, if a propagation checker is set.
+func (s dnsRecordSolver) wait(fqdn, value string) error {
+	if s.propagation == nil {
+		return nil
+	}
+	return s.propagation.Wait(fqdn, value)
+}
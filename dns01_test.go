@@ -0,0 +1,57 @@
+package acme
+
+import (
+	"testing"
+)
+
+type testDNSProvider struct {
+	records map[string]string
+}
+
+func (p *testDNSProvider) Present(fqdn, value string) error {
+	p.records[fqdn] = value
+	return nil
+}
+
+func (p *testDNSProvider) CleanUp(fqdn, value string) error {
+	delete(p.records, fqdn)
+	return nil
+}
+
+func TestDNS01RecordName(t *testing.T) {
+	tests := map[string]string{
+		"example.com":     "_acme-challenge.example.com.",
+		"example.com.":    "_acme-challenge.example.com.",
+		"*.example.com":   "_acme-challenge.example.com.",
+		"www.example.com": "_acme-challenge.www.example.com.",
+	}
+	for domain, expected := range tests {
+		if name := DNS01RecordName(domain); name != expected {
+			t.Errorf("%s: expected %s, got %s", domain, expected, name)
+		}
+	}
+}
+
+func TestDNS01Solver(t *testing.T) {
+	provider := &testDNSProvider{records: map[string]string{}}
+	solver := DNS01Solver{Provider: provider}
+	auth := Authorization{Identifier: Identifier{Type: "dns", Value: "example.com"}, Wildcard: true}
+	chal := Challenge{Type: ChallengeTypeDNS01, KeyAuthorization: "token.thumbprint"}
+
+	if err := solver.Present(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := provider.records["_acme-challenge.example.com."]; v != EncodeDNS01KeyAuthorization(chal.KeyAuthorization) {
+		t.Fatalf("unexpected record value: %q", v)
+	}
+	if err := solver.CleanUp(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(provider.records) != 0 {
+		t.Fatalf("expected records removed, got: %v", provider.records)
+	}
+
+	if err := (DNS01Solver{}).Present(Account{}, auth, chal); err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
@@ -0,0 +1,78 @@
+package acme
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"strings"
+)
+
+// DNSAccount01Label returns the account specific label of the dns-account-01 record name: the lowercase base32
+// encoding of the first 10 bytes of the sha256 hash of the account url.
+// See https://datatracker.ietf.org/doc/draft-ietf-acme-dns-account-label/
+func DNSAccount01Label(accountURL string) string {
+	hash := sha256.Sum256([]byte(accountURL))
+	return strings.ToLower(base32.StdEncoding.EncodeToString(hash[0:10]))
+}
+
+// DNSAccount01RecordName returns the fully qualified domain name of the TXT record used for a dns-account-01
+// challenge for an account and dns identifier, "_<label>._acme-challenge.<domain>.", allowing several accounts to
+// validate the same domain at once. Any wildcard prefix is removed, as wildcard authorizations are validated at the
+// base domain.
+func DNSAccount01RecordName(accountURL, domain string) string {
+	return "_" + DNSAccount01Label(accountURL) + "." + DNS01RecordName(domain)
+}
+
+// EncodeDNSAccount01KeyAuthorization encodes a key authorization and provides a value to be put in the TXT record
+// for a dns-account-01 challenge, which is the same as for dns-01.
+func EncodeDNSAccount01KeyAuthorization(keyAuth string) string {
+	return EncodeDNS01KeyAuthorization(keyAuth)
+}
+
+// DNSAccount01Solver is a Solver for dns-account-01 challenges which publishes the account specific TXT record using
+// a DNSProvider.
+type DNSAccount01Solver struct {
+	Provider DNSProvider
+
+	// Propagation, optional, see DNS01Solver.Propagation.
+	Propagation *DNSPropagationChecker
+}
+
+// Helper function to get the record name and value for a challenge.
+func (s DNSAccount01Solver) recordFor(account Account, auth Authorization, chal Challenge) (string, string, error) {
+	if account.URL == "" {
+		return "", "", errors.New("acme: no account url for dns-account-01 record")
+	}
+	return DNSAccount01RecordName(account.URL, auth.Identifier.Value), EncodeDNSAccount01KeyAuthorization(chal.KeyAuthorization), nil
+}
+
+func (s DNSAccount01Solver) record() dnsRecordSolver {
+	return dnsRecordSolver{provider: s.Provider, propagation: s.Propagation}
+}
+
+// Present publishes the TXT record for the challenge.
+func (s DNSAccount01Solver) Present(account Account, auth Authorization, chal Challenge) error {
+	fqdn, value, err := s.recordFor(account, auth, chal)
+	if err != nil {
+		return err
+	}
+	return s.record().present(fqdn, value)
+}
+
+// CleanUp removes the TXT record for the challenge.
+func (s DNSAccount01Solver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	fqdn, value, err := s.recordFor(account, auth, chal)
+	if err != nil {
+		return err
+	}
+	return s.record().cleanUp(fqdn, value)
+}
+
+// Wait waits for the TXT record to propagate if a propagation checker is set, implementing SolverWaiter.
+func (s DNSAccount01Solver) Wait(account Account, auth Authorization, chal Challenge) error {
+	fqdn, value, err := s.recordFor(account, auth, chal)
+	if err != nil {
+		return err
+	}
+	return s.record().wait(fqdn, value)
+}
@@ -0,0 +1,67 @@
+package acme
+
+import (
+	"testing"
+)
+
+func TestDNSAccount01RecordName(t *testing.T) {
+	// label is base32(sha256(account url)[0:10]), 16 lowercase characters
+	label := DNSAccount01Label("https://example.com/acme/acct/1")
+	if len(label) != 16 || label != DNSAccount01Label("https://example.com/acme/acct/1") {
+		t.Fatalf("unexpected label: %q", label)
+	}
+	if label == DNSAccount01Label("https://example.com/acme/acct/2") {
+		t.Fatal("expected different labels for different accounts")
+	}
+
+	tests := map[string]string{
+		"example.com":      "_" + label + "._acme-challenge.example.com.",
+		"*.example.com":    "_" + label + "._acme-challenge.example.com.",
+		"www.Example.com.": "_" + label + "._acme-challenge.www.Example.com.",
+	}
+	for domain, expected := range tests {
+		if name := DNSAccount01RecordName("https://example.com/acme/acct/1", domain); name != expected {
+			t.Errorf("%s: expected %s, got %s", domain, expected, name)
+		}
+	}
+
+	if v := EncodeDNSAccount01KeyAuthorization("token.thumbprint"); v != EncodeDNS01KeyAuthorization("token.thumbprint") {
+		t.Fatalf("unexpected value: %q", v)
+	}
+}
+
+func TestDNSAccount01Solver(t *testing.T) {
+	provider := &testDNSProvider{records: map[string]string{}}
+	solver := DNSAccount01Solver{Provider: provider}
+	auth := Authorization{Identifier: Identifier{Type: "dns", Value: "example.com"}, Wildcard: true}
+	chal := Challenge{Type: ChallengeTypeDNSAccount01, KeyAuthorization: "token.thumbprint"}
+
+	accounts := []Account{{URL: "https://example.com/acme/acct/1"}, {URL: "https://example.com/acme/acct/2"}}
+	for _, account := range accounts {
+		if err := solver.Present(account, auth, chal); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(provider.records) != 2 {
+		t.Fatalf("expected a record per account, got: %v", provider.records)
+	}
+	if v := provider.records[DNSAccount01RecordName(accounts[0].URL, "example.com")]; v != EncodeDNS01KeyAuthorization(chal.KeyAuthorization) {
+		t.Fatalf("unexpected record value: %q", v)
+	}
+	if err := solver.Wait(accounts[0], auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := solver.CleanUp(accounts[0], auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(provider.records) != 1 {
+		t.Fatalf("expected one record remaining, got: %v", provider.records)
+	}
+
+	if err := solver.Present(Account{}, auth, chal); err == nil {
+		t.Fatal("expected no account url error, got none")
+	}
+	if err := (DNSAccount01Solver{}).Present(accounts[0], auth, chal); err == nil {
+		t.Fatal("expected no provider error, got none")
+	}
+}
@@ -0,0 +1,377 @@
+package acme
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Minimal DNS wire format support, enough to send dynamic updates and query authoritative nameservers without
+// external dependencies.
+// See https://tools.ietf.org/html/rfc1035#section-4
+
+const (
+	dnsTypeA     = 1
+	dnsTypeNS    = 2
+	dnsTypeCNAME = 5
+	dnsTypeSOA   = 6
+	dnsTypeTXT   = 16
+	dnsTypeAAAA  = 28
+	dnsTypeTSIG  = 250
+	dnsTypeANY   = 255
+
+	dnsClassINET = 1
+	dnsClassNONE = 254
+	dnsClassANY  = 255
+
+	dnsOpcodeQuery  = 0
+	dnsOpcodeUpdate = 5
+
+	dnsFlagResponse      = 1 << 15
+	dnsFlagAuthoritative = 1 << 10
+	dnsFlagTruncated     = 1 << 9
+	dnsFlagRecursion     = 1 << 8
+
+	dnsRcodeSuccess  = 0
+	dnsRcodeNXDomain = 3
+	dnsRcodeNotAuth  = 9
+
+	dnsHeaderLen = 12
+)
+
+var dnsRcodeNames = map[int]string{
+	0: "NOERROR", 1: "FORMERR", 2: "SERVFAIL", 3: "NXDOMAIN", 4: "NOTIMP", 5: "REFUSED", 6: "YXDOMAIN", 7: "YXRRSET",
+	8: "NXRRSET", 9: "NOTAUTH", 10: "NOTZONE", 16: "BADSIG", 17: "BADKEY", 18: "BADTIME",
+}
+
+// Helper function to describe a dns response code.
+func dnsRcodeName(rcode int) string {
+	if name, ok := dnsRcodeNames[rcode]; ok {
+		return name
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
+
+type dnsQuestion struct {
+	name  string
+	typ   uint16
+	class uint16
+}
+
+type dnsRR struct {
+	name  string
+	typ   uint16
+	class uint16
+	ttl   uint32
+	data  []byte
+
+	// offsets of the record and its data in a parsed message, for decompressing names in the data
+	off     int
+	dataOff int
+}
+
+// dnsMsg is a dns message. For update messages the sections are zone, prerequisite, update and additional.
+// See https://tools.ietf.org/html/rfc2136#section-2
+type dnsMsg struct {
+	id          uint16
+	flags       uint16
+	questions   []dnsQuestion
+	answers     []dnsRR
+	authorities []dnsRR
+	additionals []dnsRR
+}
+
+func (m dnsMsg) opcode() int {
+	return int(m.flags>>11) & 0xf
+}
+
+func (m dnsMsg) rcode() int {
+	return int(m.flags & 0xf)
+}
+
+// Helper function to build the flags of a message with an opcode.
+func dnsOpcodeFlags(opcode int) uint16 {
+	return uint16(opcode&0xf) << 11
+}
+
+// Helper function to encode a domain name in uncompressed wire format.
+func packDNSName(name string) ([]byte, error) {
+	name = strings.TrimSuffix(name, ".")
+	var b []byte
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if len(label) == 0 || len(label) > 63 {
+				return nil, fmt.Errorf("acme: invalid dns name: %q", name)
+			}
+			b = append(b, byte(len(label)))
+			b = append(b, label...)
+		}
+	}
+	b = append(b, 0)
+	if len(b) > 255 {
+		return nil, fmt.Errorf("acme: dns name too long: %q", name)
+	}
+	return b, nil
+}
+
+// Helper function to read a possibly compressed domain name from a message, returning the fully qualified name and
+// the offset following the name.
+func readDNSName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errors.New("acme: dns name overflows message")
+		}
+		c := int(msg[off])
+		switch c & 0xc0 {
+		case 0x00:
+			if c == 0 {
+				if end < 0 {
+					end = off + 1
+				}
+				return strings.Join(labels, ".") + ".", end, nil
+			}
+			if off+1+c > len(msg) {
+				return "", 0, errors.New("acme: dns label overflows message")
+			}
+			labels = append(labels, string(msg[off+1:off+1+c]))
+			off += 1 + c
+		case 0xc0:
+			if off+1 >= len(msg) {
+				return "", 0, errors.New("acme: dns pointer overflows message")
+			}
+			if jumps++; jumps > 32 {
+				return "", 0, errors.New("acme: too many dns name compression pointers")
+			}
+			if end < 0 {
+				end = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3fff)
+		default:
+			return "", 0, errors.New("acme: invalid dns label")
+		}
+	}
+}
+
+// Helper function to compare dns names, ignoring case and any trailing dot.
+func dnsNameEqual(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
+
+// Helper function to check whether a name is equal to or a subdomain of a parent domain, ignoring case.
+func dnsIsSubdomain(name, parent string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	parent = strings.ToLower(strings.TrimSuffix(parent, "."))
+	return parent == "" || name == parent || strings.HasSuffix(name, "."+parent)
+}
+
+// Helper function to encode TXT record data, splitting the value into character strings of up to 255 bytes.
+func dnsTXTData(value string) []byte {
+	var b []byte
+	for {
+		n := len(value)
+		if n > 255 {
+			n = 255
+		}
+		b = append(b, byte(n))
+		b = append(b, value[:n]...)
+		value = value[n:]
+		if len(value) == 0 {
+			return b
+		}
+	}
+}
+
+// Helper function to decode TXT record data, concatenating the character strings.
+func parseDNSTXTData(data []byte) (string, error) {
+	var s []byte
+	for len(data) > 0 {
+		n := int(data[0])
+		if 1+n > len(data) {
+			return "", errors.New("acme: txt string overflows record")
+		}
+		s = append(s, data[1:1+n]...)
+		data = data[1+n:]
+	}
+	return string(s), nil
+}
+
+// pack encodes a message in wire format, without name compression.
+func (m dnsMsg) pack() ([]byte, error) {
+	b := make([]byte, dnsHeaderLen, 512)
+	binary.BigEndian.PutUint16(b[0:], m.id)
+	binary.BigEndian.PutUint16(b[2:], m.flags)
+	binary.BigEndian.PutUint16(b[4:], uint16(len(m.questions)))
+	binary.BigEndian.PutUint16(b[6:], uint16(len(m.answers)))
+	binary.BigEndian.PutUint16(b[8:], uint16(len(m.authorities)))
+	binary.BigEndian.PutUint16(b[10:], uint16(len(m.additionals)))
+
+	for _, q := range m.questions {
+		name, err := packDNSName(q.name)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, name...)
+		b = append(b, byte(q.typ>>8), byte(q.typ), byte(q.class>>8), byte(q.class))
+	}
+
+	for _, section := range [][]dnsRR{m.answers, m.authorities, m.additionals} {
+		for _, rr := range section {
+			name, err := packDNSName(rr.name)
+			if err != nil {
+				return nil, err
+			}
+			if len(rr.data) > 0xffff {
+				return nil, errors.New("acme: dns record data too long")
+			}
+			b = append(b, name...)
+			var hdr [10]byte
+			binary.BigEndian.PutUint16(hdr[0:], rr.typ)
+			binary.BigEndian.PutUint16(hdr[2:], rr.class)
+			binary.BigEndian.PutUint32(hdr[4:], rr.ttl)
+			binary.BigEndian.PutUint16(hdr[8:], uint16(len(rr.data)))
+			b = append(b, hdr[:]...)
+			b = append(b, rr.data...)
+		}
+	}
+
+	return b, nil
+}
+
+// parseDNSMsg decodes a message in wire format.
+func parseDNSMsg(b []byte) (dnsMsg, error) {
+	m := dnsMsg{}
+	if len(b) < dnsHeaderLen {
+		return m, errors.New("acme: dns message too short")
+	}
+	m.id = binary.BigEndian.Uint16(b[0:])
+	m.flags = binary.BigEndian.Uint16(b[2:])
+	counts := []int{
+		int(binary.BigEndian.Uint16(b[4:])),
+		int(binary.BigEndian.Uint16(b[6:])),
+		int(binary.BigEndian.Uint16(b[8:])),
+		int(binary.BigEndian.Uint16(b[10:])),
+	}
+
+	off := dnsHeaderLen
+	for i := 0; i < counts[0]; i++ {
+		name, next, err := readDNSName(b, off)
+		if err != nil {
+			return m, err
+		}
+		if next+4 > len(b) {
+			return m, errors.New("acme: dns question overflows message")
+		}
+		m.questions = append(m.questions, dnsQuestion{
+			name:  name,
+			typ:   binary.BigEndian.Uint16(b[next:]),
+			class: binary.BigEndian.Uint16(b[next+2:]),
+		})
+		off = next + 4
+	}
+
+	sections := []*[]dnsRR{&m.answers, &m.authorities, &m.additionals}
+	for i, section := range sections {
+		for j := 0; j < counts[i+1]; j++ {
+			name, next, err := readDNSName(b, off)
+			if err != nil {
+				return m, err
+			}
+			if next+10 > len(b) {
+				return m, errors.New("acme: dns record overflows message")
+			}
+			rr := dnsRR{
+				name:    name,
+				typ:     binary.BigEndian.Uint16(b[next:]),
+				class:   binary.BigEndian.Uint16(b[next+2:]),
+				ttl:     binary.BigEndian.Uint32(b[next+4:]),
+				off:     off,
+				dataOff: next + 10,
+			}
+			dataLen := int(binary.BigEndian.Uint16(b[next+8:]))
+			if rr.dataOff+dataLen > len(b) {
+				return m, errors.New("acme: dns record data overflows message")
+			}
+			rr.data = b[rr.dataOff : rr.dataOff+dataLen]
+			*section = append(*section, rr)
+			off = rr.dataOff + dataLen
+		}
+	}
+
+	return m, nil
+}
+
+// Helper function to generate a random message id.
+func dnsMsgID() uint16 {
+	var b [2]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		return uint16(time.Now().UnixNano())
+	}
+	return binary.BigEndian.Uint16(b[:])
+}
+
+// Helper function to send a message to a nameserver over udp, retrying over tcp if the response is truncated, and
+// return the raw response.
+func dnsExchange(addr string, query []byte, timeout time.Duration) ([]byte, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "53")
+	}
+	deadline := time.Now().Add(timeout)
+	id := binary.BigEndian.Uint16(query)
+
+	conn, err := net.DialTimeout("udp", addr, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("acme: error connecting to nameserver %s: %v", addr, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(deadline)
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, fmt.Errorf("acme: error sending dns message to %s: %v", addr, err)
+	}
+
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("acme: error reading dns response from %s: %v", addr, err)
+		}
+		// ignore stray responses for other queries
+		if n < dnsHeaderLen || binary.BigEndian.Uint16(buf) != id {
+			continue
+		}
+		if binary.BigEndian.Uint16(buf[2:])&dnsFlagTruncated == 0 {
+			return buf[:n], nil
+		}
+		break
+	}
+
+	tcp, err := net.DialTimeout("tcp", addr, time.Until(deadline))
+	if err != nil {
+		return nil, fmt.Errorf("acme: error connecting to nameserver %s over tcp: %v", addr, err)
+	}
+	defer tcp.Close()
+	_ = tcp.SetDeadline(deadline)
+
+	if _, err := tcp.Write(append([]byte{byte(len(query) >> 8), byte(len(query))}, query...)); err != nil {
+		return nil, fmt.Errorf("acme: error sending dns message to %s over tcp: %v", addr, err)
+	}
+	var l [2]byte
+	if _, err := io.ReadFull(tcp, l[:]); err != nil {
+		return nil, fmt.Errorf("acme: error reading dns response from %s over tcp: %v", addr, err)
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(l[:]))
+	if _, err := io.ReadFull(tcp, resp); err != nil {
+		return nil, fmt.Errorf("acme: error reading dns response from %s over tcp: %v", addr, err)
+	}
+	if len(resp) < dnsHeaderLen || binary.BigEndian.Uint16(resp) != id {
+		return nil, fmt.Errorf("acme: mismatched dns response from %s over tcp", addr)
+	}
+	return resp, nil
+}
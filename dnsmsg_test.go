@@ -0,0 +1,314 @@
+package acme
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testDNSServer is a local authoritative nameserver for a single zone, answering queries from its records and
+// applying signed dynamic updates.
+type testDNSServer struct {
+	addr     string
+	zone     string
+	key      *tsigKey
+	truncate bool
+
+	conn     net.PacketConn
+	listener net.Listener
+
+	mu      sync.Mutex
+	records []dnsRR
+	queries int
+	updates int
+}
+
+func newTestDNSServer(t *testing.T, zone string, key *tsigKey) *testDNSServer {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	l, err := net.Listen("tcp", conn.LocalAddr().String())
+	if err != nil {
+		conn.Close()
+		t.Fatalf("error listening: %v", err)
+	}
+	s := &testDNSServer{addr: conn.LocalAddr().String(), zone: zone, key: key, conn: conn, listener: l}
+	go s.serveUDP()
+	go s.serveTCP()
+	return s
+}
+
+func (s *testDNSServer) close() {
+	s.conn.Close()
+	s.listener.Close()
+}
+
+func (s *testDNSServer) add(rr dnsRR) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rr)
+}
+
+func (s *testDNSServer) txt(name string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var values []string
+	for _, rr := range s.records {
+		if rr.typ == dnsTypeTXT && dnsNameEqual(rr.name, name) {
+			v, _ := parseDNSTXTData(rr.data)
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func (s *testDNSServer) serveUDP() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		resp := s.handle(buf[:n])
+		if resp == nil {
+			continue
+		}
+		s.mu.Lock()
+		truncate := s.truncate
+		s.mu.Unlock()
+		if truncate {
+			m, _ := parseDNSMsg(resp)
+			resp, _ = dnsMsg{id: m.id, flags: m.flags | dnsFlagTruncated, questions: m.questions}.pack()
+		}
+		s.conn.WriteTo(resp, addr)
+	}
+}
+
+func (s *testDNSServer) serveTCP() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		var l [2]byte
+		if _, err := conn.Read(l[:]); err == nil {
+			req := make([]byte, binary.BigEndian.Uint16(l[:]))
+			if _, err := conn.Read(req); err == nil {
+				if resp := s.handle(req); resp != nil {
+					conn.Write(append([]byte{byte(len(resp) >> 8), byte(len(resp))}, resp...))
+				}
+			}
+		}
+		conn.Close()
+	}
+}
+
+func (s *testDNSServer) soa() dnsRR {
+	var data []byte
+	for _, n := range []string{"ns." + s.zone, "hostmaster." + s.zone} {
+		b, _ := packDNSName(n)
+		data = append(data, b...)
+	}
+	data = append(data, make([]byte, 20)...)
+	return dnsRR{name: s.zone, typ: dnsTypeSOA, class: dnsClassINET, ttl: 60, data: data}
+}
+
+func (s *testDNSServer) handle(req []byte) []byte {
+	m, err := parseDNSMsg(req)
+	if err != nil || len(m.questions) != 1 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := dnsMsg{
+		id:        m.id,
+		flags:     m.flags&(0xf<<11) | dnsFlagResponse | dnsFlagAuthoritative,
+		questions: m.questions,
+	}
+	q := m.questions[0]
+
+	switch m.opcode() {
+	case dnsOpcodeQuery:
+		s.queries++
+		if !dnsIsSubdomain(q.name, s.zone) {
+			resp.flags = resp.flags&^dnsFlagAuthoritative | 5
+			break
+		}
+		if q.typ == dnsTypeSOA && dnsNameEqual(q.name, s.zone) {
+			resp.answers = append(resp.answers, s.soa())
+			break
+		}
+		for _, rr := range s.records {
+			if dnsNameEqual(rr.name, q.name) && (rr.typ == q.typ || rr.typ == dnsTypeCNAME) {
+				rr.name = q.name
+				resp.answers = append(resp.answers, rr)
+			}
+		}
+		if len(resp.answers) == 0 {
+			resp.authorities = append(resp.authorities, s.soa())
+		}
+		b, _ := resp.pack()
+		return b
+
+	case dnsOpcodeUpdate:
+		s.updates++
+		var mac []byte
+		if s.key != nil {
+			if err := s.key.verify(req, nil, time.Now()); err != nil {
+				// unsigned response with the tsig error, as a real nameserver would
+				// https://tools.ietf.org/html/rfc8945#section-5.3.2
+				resp.flags |= dnsRcodeNotAuth
+				alg, _ := packDNSName(TSIGAlgorithmHMACSHA256)
+				data := append(alg, tsigTime(time.Now())...)
+				data = append(data, 1, 44, 0, 0, byte(m.id>>8), byte(m.id), 0, 16, 0, 0)
+				resp.additionals = []dnsRR{{name: s.key.name, typ: dnsTypeTSIG, class: dnsClassANY, data: data}}
+				b, _ := resp.pack()
+				return b
+			}
+			rr := m.additionals[len(m.additionals)-1]
+			t, _ := parseTSIGRecord(req, rr)
+			mac = t.mac
+		}
+		if !dnsNameEqual(q.name, s.zone) {
+			resp.flags |= 10 // NOTZONE
+			break
+		}
+		for _, rr := range m.authorities {
+			switch rr.class {
+			case dnsClassINET:
+				rr.data = append([]byte{}, rr.data...)
+				s.records = append(s.records, rr)
+			case dnsClassNONE:
+				var kept []dnsRR
+				for _, existing := range s.records {
+					if !dnsNameEqual(existing.name, rr.name) || existing.typ != rr.typ || string(existing.data) != string(rr.data) {
+						kept = append(kept, existing)
+					}
+				}
+				s.records = kept
+			}
+		}
+		b, _ := resp.pack()
+		if s.key != nil {
+			b, _, _ = s.key.sign(b, mac, time.Now())
+		}
+		return b
+	}
+
+	b, _ := resp.pack()
+	return b
+}
+
+func Test_packDNSName(t *testing.T) {
+	b, err := packDNSName("www.Example.com.")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != "\x03www\x07Example\x03com\x00" {
+		t.Fatalf("unexpected name: %q", b)
+	}
+
+	if b, err := packDNSName("."); err != nil || string(b) != "\x00" {
+		t.Fatalf("unexpected root name: %q %v", b, err)
+	}
+
+	for _, name := range []string{"a..com", strings.Repeat("a", 64) + ".com", strings.Repeat("abcdefgh.", 32)} {
+		if _, err := packDNSName(name); err == nil {
+			t.Fatalf("expected error for %q, got none", name)
+		}
+	}
+}
+
+func Test_readDNSName(t *testing.T) {
+	msg := []byte("\x07example\x03com\x00\x03www\xc0\x00\xc0\x13")
+	name, off, err := readDNSName(msg, 0)
+	if err != nil || name != "example.com." || off != 13 {
+		t.Fatalf("unexpected name: %q %d %v", name, off, err)
+	}
+	name, off, err = readDNSName(msg, 13)
+	if err != nil || name != "www.example.com." || off != 19 {
+		t.Fatalf("unexpected compressed name: %q %d %v", name, off, err)
+	}
+	if _, _, err := readDNSName(msg, 19); err == nil {
+		t.Fatal("expected pointer loop error, got none")
+	}
+	if _, _, err := readDNSName([]byte("\x07exam"), 0); err == nil {
+		t.Fatal("expected overflow error, got none")
+	}
+}
+
+func Test_dnsTXTData(t *testing.T) {
+	value := strings.Repeat("x", 300)
+	data := dnsTXTData(value)
+	if len(data) != 302 || data[0] != 255 || data[256] != 45 {
+		t.Fatalf("unexpected txt data length %d", len(data))
+	}
+	v, err := parseDNSTXTData(data)
+	if err != nil || v != value {
+		t.Fatalf("unexpected txt value: %q %v", v, err)
+	}
+	if _, err := parseDNSTXTData([]byte{5, 'a'}); err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
+
+func TestDNSMsg_pack(t *testing.T) {
+	msg := dnsMsg{
+		id:          1234,
+		flags:       dnsOpcodeFlags(dnsOpcodeUpdate),
+		questions:   []dnsQuestion{{name: "example.com.", typ: dnsTypeSOA, class: dnsClassINET}},
+		authorities: []dnsRR{{name: "_acme-challenge.example.com.", typ: dnsTypeTXT, class: dnsClassINET, ttl: 60, data: dnsTXTData("value")}},
+	}
+	b, err := msg.pack()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := parseDNSMsg(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.id != 1234 || parsed.opcode() != dnsOpcodeUpdate || len(parsed.questions) != 1 || len(parsed.authorities) != 1 {
+		t.Fatalf("unexpected message: %+v", parsed)
+	}
+	rr := parsed.authorities[0]
+	if rr.name != "_acme-challenge.example.com." || rr.typ != dnsTypeTXT || rr.ttl != 60 {
+		t.Fatalf("unexpected record: %+v", rr)
+	}
+	if v, _ := parseDNSTXTData(rr.data); v != "value" {
+		t.Fatalf("unexpected value: %q", v)
+	}
+
+	if _, err := parseDNSMsg(b[:len(b)-2]); err == nil {
+		t.Fatal("expected truncated message error, got none")
+	}
+}
+
+func Test_dnsExchange(t *testing.T) {
+	srv := newTestDNSServer(t, "example.com.", nil)
+	defer srv.close()
+	srv.add(dnsRR{name: "www.example.com.", typ: dnsTypeTXT, class: dnsClassINET, data: dnsTXTData("hello")})
+
+	for _, truncate := range []bool{false, true} {
+		srv.mu.Lock()
+		srv.truncate = truncate
+		srv.mu.Unlock()
+		query, _ := dnsMsg{id: dnsMsgID(), questions: []dnsQuestion{{name: "www.example.com.", typ: dnsTypeTXT, class: dnsClassINET}}}.pack()
+		resp, err := dnsExchange(srv.addr, query, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error (truncate %t): %v", truncate, err)
+		}
+		m, err := parseDNSMsg(resp)
+		if err != nil || len(m.answers) != 1 {
+			t.Fatalf("unexpected response (truncate %t): %+v %v", truncate, m, err)
+		}
+	}
+
+	if _, err := dnsExchange("127.0.0.1:1", []byte{0, 0}, 100*time.Millisecond); err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
@@ -0,0 +1,191 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DNSPersist01Prefix is the label prepended to a domain name for the dns-persist-01 TXT record.
+// See https://datatracker.ietf.org/doc/html/draft-ietf-acme-dns-persist-01#section-3.2
+const DNSPersist01Prefix = "_validation-persist"
+
+// DNSPersist01RecordName returns the fully qualified domain name of the persistent TXT record for a dns identifier.
+// Any wildcard prefix is removed, as wildcard authorizations are validated at the base domain.
+func DNSPersist01RecordName(domain string) string {
+	domain = strings.TrimPrefix(domain, "*.")
+	return DNSPersist01Prefix + "." + strings.TrimSuffix(domain, ".") + "."
+}
+
+// DNSPersist01Record is the value of a dns-persist-01 TXT record, authorizing an account at an issuer to validate a
+// domain until the record is removed or expires.
+type DNSPersist01Record struct {
+	// IssuerDomainName of the CA the record authorizes, one of the challenge IssuerDomainNames.
+	IssuerDomainName string
+
+	// AccountURI authorized by the record, the challenge AccountURI.
+	AccountURI string
+
+	// Wildcard sets "policy=wildcard", also authorizing wildcard certificates for the domain.
+	Wildcard bool
+
+	// PersistUntil is the time the record is valid until, optional.
+	PersistUntil time.Time
+
+	// Params are any other parameters of the record.
+	Params map[string]string
+}
+
+// String formats the record as a TXT record value, eg
+// "ca.example; accounturi=https://ca.example/acct/1; policy=wildcard; persistUntil=1767225600"
+func (r DNSPersist01Record) String() string {
+	parts := []string{r.IssuerDomainName}
+	if r.AccountURI != "" {
+		parts = append(parts, "accounturi="+r.AccountURI)
+	}
+	if r.Wildcard {
+		parts = append(parts, "policy=wildcard")
+	}
+	if !r.PersistUntil.IsZero() {
+		parts = append(parts, "persistUntil="+strconv.FormatInt(r.PersistUntil.Unix(), 10))
+	}
+
+	var keys []string
+	for k := range r.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+r.Params[k])
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// ParseDNSPersist01Record parses the value of a dns-persist-01 TXT record. Parameter names are case insensitive.
+func ParseDNSPersist01Record(value string) (DNSPersist01Record, error) {
+	r := DNSPersist01Record{}
+
+	parts := strings.Split(value, ";")
+	r.IssuerDomainName = strings.TrimSpace(parts[0])
+	if r.IssuerDomainName == "" || strings.ContainsAny(r.IssuerDomainName, " \t=") {
+		return r, fmt.Errorf("acme: invalid dns-persist-01 issuer domain name: %q", r.IssuerDomainName)
+	}
+
+	seen := map[string]bool{}
+	for _, p := range parts[1:] {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		idx := strings.Index(p, "=")
+		if idx < 1 {
+			return r, fmt.Errorf("acme: invalid dns-persist-01 parameter: %q", p)
+		}
+		k, v := strings.TrimSpace(p[:idx]), strings.TrimSpace(p[idx+1:])
+		lk := strings.ToLower(k)
+		if seen[lk] {
+			return r, fmt.Errorf("acme: duplicate dns-persist-01 parameter: %q", k)
+		}
+		seen[lk] = true
+
+		switch lk {
+		case "accounturi":
+			r.AccountURI = v
+		case "policy":
+			r.Wildcard = strings.EqualFold(v, "wildcard")
+		case "persistuntil":
+			secs, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || secs < 0 {
+				return r, fmt.Errorf("acme: invalid dns-persist-01 persistUntil: %q", v)
+			}
+			r.PersistUntil = time.Unix(secs, 0)
+		default:
+			if r.Params == nil {
+				r.Params = map[string]string{}
+			}
+			r.Params[k] = v
+		}
+	}
+
+	return r, nil
+}
+
+// CheckDNSPersist01Record checks whether a parsed dns-persist-01 record satisfies a challenge for an authorization:
+// the issuer domain name must be one accepted by the challenge, the account uri must match, wildcard authorizations
+// require the wildcard policy, and the record must not have expired at the time provided.
+func CheckDNSPersist01Record(record DNSPersist01Record, auth Authorization, chal Challenge, now time.Time) error {
+	if chal.AccountURI == "" {
+		return errors.New("acme: no account uri in dns-persist-01 challenge")
+	}
+	if !containsString(chal.IssuerDomainNames, strings.TrimSuffix(record.IssuerDomainName, ".")) {
+		return fmt.Errorf("acme: dns-persist-01 record issuer %q not in challenge issuer domain names %v", record.IssuerDomainName, chal.IssuerDomainNames)
+	}
+	if record.AccountURI != chal.AccountURI {
+		return fmt.Errorf("acme: dns-persist-01 record account uri %q does not match %q", record.AccountURI, chal.AccountURI)
+	}
+	if (auth.Wildcard || strings.HasPrefix(auth.Identifier.Value, "*.")) && !record.Wildcard {
+		return errors.New("acme: dns-persist-01 record does not allow wildcard")
+	}
+	if !record.PersistUntil.IsZero() && !now.Before(record.PersistUntil) {
+		return fmt.Errorf("acme: dns-persist-01 record expired at %s", record.PersistUntil)
+	}
+	return nil
+}
+
+// LookupDNSPersist01Record looks up the published dns-persist-01 TXT records for an authorization and returns the
+// first one which satisfies the challenge, see CheckDNSPersist01Record. If the resolver is nil, net.DefaultResolver
+// is used.
+func LookupDNSPersist01Record(resolver *net.Resolver, auth Authorization, chal Challenge) (DNSPersist01Record, error) {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	name := DNSPersist01RecordName(auth.Identifier.Value)
+
+	ctx, cancel := context.WithTimeout(context.Background(), propagationDefaultQueryTimeout)
+	defer cancel()
+	values, err := resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return DNSPersist01Record{}, fmt.Errorf("acme: error looking up %s: %v", name, err)
+	}
+
+	errs := []string{}
+	now := time.Now()
+	for _, v := range values {
+		record, err := ParseDNSPersist01Record(v)
+		if err == nil {
+			err = CheckDNSPersist01Record(record, auth, chal, now)
+		}
+		if err == nil {
+			return record, nil
+		}
+		errs = append(errs, err.Error())
+	}
+
+	return DNSPersist01Record{}, fmt.Errorf("acme: no dns-persist-01 record at %s satisfies the challenge: %s", name, strings.Join(errs, "; "))
+}
+
+// DNSPersist01Solver is a Solver for dns-persist-01 challenges which have a record already published, eg by a one
+// off DNSProvider.Present of a DNSPersist01Record. Present checks the published record satisfies the challenge so
+// validation isn't attempted otherwise, no dns changes are made.
+type DNSPersist01Solver struct {
+	// Resolver used to look up the record.
+	// Default net.DefaultResolver if not set.
+	Resolver *net.Resolver
+}
+
+// Present checks a record satisfying the challenge is published.
+func (s DNSPersist01Solver) Present(account Account, auth Authorization, chal Challenge) error {
+	_, err := LookupDNSPersist01Record(s.Resolver, auth, chal)
+	return err
+}
+
+// CleanUp does nothing, the record is persistent.
+func (s DNSPersist01Solver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	return nil
+}
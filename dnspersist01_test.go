@@ -0,0 +1,127 @@
+package acme
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDNSPersist01Record(t *testing.T) {
+	if name := DNSPersist01RecordName("*.example.com"); name != "_validation-persist.example.com." {
+		t.Fatalf("unexpected record name: %s", name)
+	}
+
+	until := time.Unix(1767225600, 0)
+	record := DNSPersist01Record{
+		IssuerDomainName: "ca.example",
+		AccountURI:       "https://ca.example/acct/1",
+		Wildcard:         true,
+		PersistUntil:     until,
+		Params:           map[string]string{"z": "1", "a": "2"},
+	}
+	value := record.String()
+	expected := "ca.example; accounturi=https://ca.example/acct/1; policy=wildcard; persistUntil=1767225600; a=2; z=1"
+	if value != expected {
+		t.Fatalf("unexpected value:\n%s\nexpected:\n%s", value, expected)
+	}
+
+	parsed, err := ParseDNSPersist01Record(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.String() != value || !parsed.PersistUntil.Equal(until) || !parsed.Wildcard {
+		t.Fatalf("unexpected parsed record: %+v", parsed)
+	}
+
+	parsed, err = ParseDNSPersist01Record("  ca.example ;AccountURI = https://ca.example/acct/1;POLICY=Wildcard;")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.IssuerDomainName != "ca.example" || parsed.AccountURI != "https://ca.example/acct/1" || !parsed.Wildcard || !parsed.PersistUntil.IsZero() {
+		t.Fatalf("unexpected parsed record: %+v", parsed)
+	}
+
+	for _, bad := range []string{"", "; accounturi=x", "ca.example; accounturi", "ca.example; persistUntil=soon", "ca.example; policy=a; policy=b", "accounturi=x"} {
+		if _, err := ParseDNSPersist01Record(bad); err == nil {
+			t.Errorf("expected error parsing %q, got none", bad)
+		}
+	}
+}
+
+func TestCheckDNSPersist01Record(t *testing.T) {
+	now := time.Now()
+	auth := Authorization{Identifier: Identifier{Type: "dns", Value: "example.com"}}
+	chal := Challenge{
+		Type:              ChallengeTypeDNSPersist01,
+		AccountURI:        "https://ca.example/acct/1",
+		IssuerDomainNames: []string{"other.example", "ca.example"},
+	}
+	record := DNSPersist01Record{IssuerDomainName: "CA.example", AccountURI: chal.AccountURI}
+
+	if err := CheckDNSPersist01Record(record, auth, chal, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		record DNSPersist01Record
+		auth   Authorization
+		chal   Challenge
+	}{
+		{name: "no account uri", record: record, auth: auth},
+		{name: "issuer", record: DNSPersist01Record{IssuerDomainName: "evil.example", AccountURI: chal.AccountURI}, auth: auth, chal: chal},
+		{name: "account", record: DNSPersist01Record{IssuerDomainName: "ca.example", AccountURI: "https://ca.example/acct/2"}, auth: auth, chal: chal},
+		{name: "wildcard", record: record, auth: Authorization{Identifier: auth.Identifier, Wildcard: true}, chal: chal},
+		{name: "expired", record: DNSPersist01Record{IssuerDomainName: "ca.example", AccountURI: chal.AccountURI, PersistUntil: now}, auth: auth, chal: chal},
+	}
+	for _, ct := range tests {
+		if err := CheckDNSPersist01Record(ct.record, ct.auth, ct.chal, now); err == nil {
+			t.Errorf("%s: expected error, got none", ct.name)
+		}
+	}
+
+	record.Wildcard = true
+	record.PersistUntil = now.Add(time.Hour)
+	if err := CheckDNSPersist01Record(record, Authorization{Identifier: auth.Identifier, Wildcard: true}, chal, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDNSPersist01Solver(t *testing.T) {
+	srv := newTestDNSServer(t, "example.com.", nil)
+	defer srv.close()
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, srv.addr)
+		},
+	}
+
+	solver := DNSPersist01Solver{Resolver: resolver}
+	auth := Authorization{Identifier: Identifier{Type: "dns", Value: "example.com"}}
+	chal := Challenge{Type: ChallengeTypeDNSPersist01, AccountURI: "https://ca.example/acct/1", IssuerDomainNames: []string{"ca.example"}}
+
+	if err := solver.Present(Account{}, auth, chal); err == nil {
+		t.Fatal("expected error without record, got none")
+	}
+
+	name := DNSPersist01RecordName("example.com")
+	srv.add(dnsRR{name: name, typ: dnsTypeTXT, class: dnsClassINET, data: dnsTXTData("ca.example; accounturi=https://ca.example/acct/2")})
+	if err := solver.Present(Account{}, auth, chal); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected account mismatch error, got: %v", err)
+	}
+
+	srv.add(dnsRR{name: name, typ: dnsTypeTXT, class: dnsClassINET, data: dnsTXTData(DNSPersist01Record{IssuerDomainName: "ca.example", AccountURI: chal.AccountURI}.String())})
+	if err := solver.Present(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := solver.CleanUp(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(srv.txt(name)) != 2 {
+		t.Fatal("expected records to persist after clean up")
+	}
+}
@@ -0,0 +1,219 @@
+package acme
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// EmailReply00Challenge contains the fields of a challenge email sent by an acme server for the email-reply-00
+// challenge.
+// See https://tools.ietf.org/html/rfc8823#section-3
+type EmailReply00Challenge struct {
+	// From is the address the challenge email was sent from, which the response must be sent to.
+	From string
+
+	// To is the email address being validated, which the response must be sent from.
+	To string
+
+	// TokenPart1 is the first part of the challenge token, provided in the subject of the challenge email.
+	TokenPart1 string
+
+	// MessageID of the challenge email, referenced by the response.
+	MessageID string
+}
+
+// EmailTransport sends and receives the emails used by the email-reply-00 challenge.
+type EmailTransport interface {
+	// Receive waits for the challenge email sent by the acme server from the from address to the to address and
+	// returns the raw RFC 5322 message.
+	Receive(to, from string) ([]byte, error)
+
+	// Send sends a raw RFC 5322 message. The response email must be signed with DKIM or S/MIME, either by the
+	// transport or the mail system it submits to.
+	Send(from string, to []string, msg []byte) error
+}
+
+// ParseEmailReply00Challenge parses a raw challenge email received for an email-reply-00 challenge, checking the
+// required headers are present and the sender matches the challenge.
+//
+// This function does not verify the DKIM or S/MIME signature of the email, which RFC 8823 requires clients to do
+// before responding. See EmailReply00Solver.
+func ParseEmailReply00Challenge(raw []byte, chal Challenge) (EmailReply00Challenge, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return EmailReply00Challenge{}, fmt.Errorf("acme: error reading challenge email: %v", err)
+	}
+
+	autoSubmitted := strings.ToLower(msg.Header.Get("Auto-Submitted"))
+	if !strings.HasPrefix(autoSubmitted, "auto-generated") || !strings.Contains(strings.Replace(autoSubmitted, " ", "", -1), ";type=acme") {
+		return EmailReply00Challenge{}, fmt.Errorf("acme: challenge email has invalid Auto-Submitted header: %q", autoSubmitted)
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return EmailReply00Challenge{}, fmt.Errorf("acme: error parsing challenge email From header: %v", err)
+	}
+	if chal.From != "" && !strings.EqualFold(from.Address, chal.From) {
+		return EmailReply00Challenge{}, fmt.Errorf("acme: challenge email from %q, expected %q", from.Address, chal.From)
+	}
+
+	to, err := mail.ParseAddress(msg.Header.Get("To"))
+	if err != nil {
+		return EmailReply00Challenge{}, fmt.Errorf("acme: error parsing challenge email To header: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		return EmailReply00Challenge{}, fmt.Errorf("acme: error decoding challenge email subject: %v", err)
+	}
+	subject = strings.TrimSpace(subject)
+	if !strings.HasPrefix(subject, "ACME: ") {
+		return EmailReply00Challenge{}, fmt.Errorf("acme: challenge email has invalid subject: %q", subject)
+	}
+	tokenPart1 := strings.TrimSpace(strings.TrimPrefix(subject, "ACME: "))
+	if tokenPart1 == "" {
+		return EmailReply00Challenge{}, errors.New("acme: challenge email has no token")
+	}
+	// token-part1 is base64url, anything else could inject headers into the response subject
+	if !isBase64URL(tokenPart1) {
+		return EmailReply00Challenge{}, fmt.Errorf("acme: challenge email has invalid token: %q", tokenPart1)
+	}
+
+	return EmailReply00Challenge{
+		From:       from.Address,
+		To:         to.Address,
+		TokenPart1: tokenPart1,
+		MessageID:  msg.Header.Get("Message-ID"),
+	}, nil
+}
+
+// EmailReply00KeyAuthorization returns the key authorization for an email-reply-00 challenge, where the token is the
+// concatenation of the token-part1 from the challenge email and the token-part2 from the challenge object.
+// Note the KeyAuthorization field populated by FetchAuthorization only contains token-part2 so cannot be used.
+// See https://tools.ietf.org/html/rfc8823#section-3.1
+func EmailReply00KeyAuthorization(tokenPart1 string, chal Challenge, thumbprint string) string {
+	return tokenPart1 + chal.Token + "." + thumbprint
+}
+
+// BuildEmailReply00Response builds the raw response email for an email-reply-00 challenge given the parsed challenge
+// email and key authorization. The body contains the base64url encoded SHA-256 digest of the key authorization.
+// Fields of the challenge email containing line breaks are refused, as they would inject headers into the response.
+// See https://tools.ietf.org/html/rfc8823#section-3.2
+func BuildEmailReply00Response(challengeEmail EmailReply00Challenge, keyAuth string) ([]byte, error) {
+	for name, v := range map[string]string{
+		"From":       challengeEmail.From,
+		"To":         challengeEmail.To,
+		"TokenPart1": challengeEmail.TokenPart1,
+		"MessageID":  challengeEmail.MessageID,
+	} {
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, fmt.Errorf("acme: challenge email %s contains a line break: %q", name, v)
+		}
+	}
+
+	messageID := make([]byte, 16)
+	_, _ = rand.Read(messageID)
+	domain := challengeEmail.To[strings.LastIndex(challengeEmail.To, "@")+1:]
+
+	headers := []string{
+		"From: " + challengeEmail.To,
+		"To: " + challengeEmail.From,
+		"Subject: Re: ACME: " + challengeEmail.TokenPart1,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: <" + hex.EncodeToString(messageID) + "@" + domain + ">",
+		"Auto-Submitted: auto-replied; type=acme",
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=us-ascii",
+	}
+	if challengeEmail.MessageID != "" {
+		headers = append(headers,
+			"In-Reply-To: "+challengeEmail.MessageID,
+			"References: "+challengeEmail.MessageID)
+	}
+
+	body := []string{
+		"-----BEGIN ACME RESPONSE-----",
+		EncodeDNS01KeyAuthorization(keyAuth),
+		"-----END ACME RESPONSE-----",
+	}
+
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.Join(body, "\r\n") + "\r\n"), nil
+}
+
+// Helper function to check a string only contains base64url characters.
+func isBase64URL(s string) bool {
+	for _, r := range s {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// EmailReply00Solver is a Solver for the email-reply-00 challenge. Present waits for the challenge email, verifies it,
+// and sends the response email using the transport.
+type EmailReply00Solver struct {
+	// Transport used to receive the challenge email and send the response.
+	Transport EmailTransport
+
+	// Verify checks the DKIM or S/MIME signature of the raw challenge email, as required by RFC 8823 section 3.
+	// Must be set unless SkipVerify is set.
+	Verify func(raw []byte) error
+
+	// SkipVerify responds to challenge emails without verifying their signature, eg if the mail system has already
+	// verified it.
+	SkipVerify bool
+}
+
+// Present implements Solver
+func (s EmailReply00Solver) Present(account Account, auth Authorization, chal Challenge) error {
+	if s.Transport == nil {
+		return errors.New("acme: no email transport")
+	}
+	if s.Verify == nil && !s.SkipVerify {
+		return errors.New("acme: no email signature verification")
+	}
+
+	raw, err := s.Transport.Receive(auth.Identifier.Value, chal.From)
+	if err != nil {
+		return fmt.Errorf("acme: error receiving challenge email: %v", err)
+	}
+
+	if s.Verify != nil {
+		if err := s.Verify(raw); err != nil {
+			return fmt.Errorf("acme: error verifying challenge email: %v", err)
+		}
+	}
+
+	challengeEmail, err := ParseEmailReply00Challenge(raw, chal)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(challengeEmail.To, auth.Identifier.Value) {
+		return fmt.Errorf("acme: challenge email to %q, expected %q", challengeEmail.To, auth.Identifier.Value)
+	}
+
+	keyAuth := EmailReply00KeyAuthorization(challengeEmail.TokenPart1, chal, account.Thumbprint)
+	response, err := BuildEmailReply00Response(challengeEmail, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Transport.Send(challengeEmail.To, []string{challengeEmail.From}, response); err != nil {
+		return fmt.Errorf("acme: error sending response email: %v", err)
+	}
+
+	return nil
+}
+
+// CleanUp implements Solver
+func (s EmailReply00Solver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	return nil
+}
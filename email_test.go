@@ -0,0 +1,211 @@
+package acme
+
+import (
+	"bytes"
+	"errors"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+const testChallengeEmail = "Auto-Submitted: auto-generated; type=acme\r\n" +
+	"Date: Sat, 5 Dec 2020 10:08:55 +0100\r\n" +
+	"Message-ID: <A2299BB.FF7788@example.org>\r\n" +
+	"From: acme-generator@example.org\r\n" +
+	"To: alexey@example.com\r\n" +
+	"Subject: ACME: bELa8eY7E2nhP9z5Wa7WoDRqAlPNs8Hw\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"\r\n" +
+	"This is an automatically generated ACME challenge for email address\r\n" +
+	"\"alexey@example.com\".\r\n"
+
+// memoryEmailTransport is an in-memory stand-in for a mail system
+type memoryEmailTransport struct {
+	inbox []byte
+	sent  []byte
+	to    []string
+}
+
+func (m *memoryEmailTransport) Receive(to, from string) ([]byte, error) {
+	if m.inbox == nil {
+		return nil, errors.New("no mail")
+	}
+	return m.inbox, nil
+}
+
+func (m *memoryEmailTransport) Send(from string, to []string, msg []byte) error {
+	m.to = to
+	m.sent = msg
+	return nil
+}
+
+func TestParseEmailReply00Challenge(t *testing.T) {
+	chal := Challenge{Type: ChallengeTypeEmailReply00, From: "acme-generator@example.org"}
+
+	challengeEmail, err := ParseEmailReply00Challenge([]byte(testChallengeEmail), chal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := EmailReply00Challenge{
+		From:       "acme-generator@example.org",
+		To:         "alexey@example.com",
+		TokenPart1: "bELa8eY7E2nhP9z5Wa7WoDRqAlPNs8Hw",
+		MessageID:  "<A2299BB.FF7788@example.org>",
+	}
+	if challengeEmail != expected {
+		t.Fatalf("expected: %+v, got: %+v", expected, challengeEmail)
+	}
+
+	tests := []struct {
+		name     string
+		raw      string
+		errorStr string
+	}{
+		{
+			name:     "not auto submitted",
+			raw:      strings.Replace(testChallengeEmail, "Auto-Submitted: auto-generated; type=acme\r\n", "", 1),
+			errorStr: "Auto-Submitted",
+		},
+		{
+			name:     "wrong sender",
+			raw:      strings.Replace(testChallengeEmail, "From: acme-generator@", "From: someone@", 1),
+			errorStr: "expected",
+		},
+		{
+			name:     "bad subject",
+			raw:      strings.Replace(testChallengeEmail, "Subject: ACME: ", "Subject: Hello ", 1),
+			errorStr: "invalid subject",
+		},
+		{
+			name:     "header injection in encoded subject",
+			raw:      strings.Replace(testChallengeEmail, "Subject: ACME: bELa8eY7E2nhP9z5Wa7WoDRqAlPNs8Hw", "Subject: =?utf-8?q?ACME:_x=0D=0ABcc:_victim@example?=", 1),
+			errorStr: "invalid token",
+		},
+		{
+			name:     "non base64url token",
+			raw:      strings.Replace(testChallengeEmail, "bELa8eY7E2nhP9z5Wa7WoDRqAlPNs8Hw", "bELa8eY7+E2nhP9z5Wa7WoDRqAlPNs8Hw", 1),
+			errorStr: "invalid token",
+		},
+		{
+			name:     "not an email",
+			raw:      "rubbish",
+			errorStr: "error reading",
+		},
+	}
+	for _, ct := range tests {
+		_, err := ParseEmailReply00Challenge([]byte(ct.raw), chal)
+		if err == nil || !strings.Contains(err.Error(), ct.errorStr) {
+			t.Errorf("%s: expected error containing %q, got: %v", ct.name, ct.errorStr, err)
+		}
+	}
+}
+
+func TestEmailReply00KeyAuthorization(t *testing.T) {
+	chal := Challenge{Token: "DGyRejmCefe7v4NfDGDKfA"}
+	keyAuth := EmailReply00KeyAuthorization("bELa8eY7E2nhP9z5Wa7WoDRqAlPNs8Hw", chal, "thumbprint")
+	if keyAuth != "bELa8eY7E2nhP9z5Wa7WoDRqAlPNs8HwDGyRejmCefe7v4NfDGDKfA.thumbprint" {
+		t.Fatalf("unexpected key authorization: %s", keyAuth)
+	}
+}
+
+func TestBuildEmailReply00Response(t *testing.T) {
+	challengeEmail := EmailReply00Challenge{
+		From:       "acme-generator@example.org",
+		To:         "alexey@example.com",
+		TokenPart1: "bELa8eY7E2nhP9z5Wa7WoDRqAlPNs8Hw",
+		MessageID:  "<A2299BB.FF7788@example.org>",
+	}
+	raw, err := BuildEmailReply00Response(challengeEmail, "keyauth")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("error reading response: %v", err)
+	}
+	headers := map[string]string{
+		"From":           "alexey@example.com",
+		"To":             "acme-generator@example.org",
+		"Subject":        "Re: ACME: bELa8eY7E2nhP9z5Wa7WoDRqAlPNs8Hw",
+		"In-Reply-To":    "<A2299BB.FF7788@example.org>",
+		"References":     "<A2299BB.FF7788@example.org>",
+		"Auto-Submitted": "auto-replied; type=acme",
+	}
+	for k, v := range headers {
+		if msg.Header.Get(k) != v {
+			t.Errorf("header %s expected %q, got: %q", k, v, msg.Header.Get(k))
+		}
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>") {
+		t.Errorf("unexpected message id: %s", msg.Header.Get("Message-ID"))
+	}
+
+	body := new(bytes.Buffer)
+	_, _ = body.ReadFrom(msg.Body)
+	expectedBody := "-----BEGIN ACME RESPONSE-----\r\n" +
+		EncodeDNS01KeyAuthorization("keyauth") + "\r\n" +
+		"-----END ACME RESPONSE-----\r\n"
+	if body.String() != expectedBody {
+		t.Fatalf("expected body %q, got: %q", expectedBody, body.String())
+	}
+
+	for _, bad := range []EmailReply00Challenge{
+		{From: challengeEmail.From, To: challengeEmail.To, TokenPart1: "x\r\nBcc: victim@example.com"},
+		{From: challengeEmail.From + "\nBcc: victim@example.com", To: challengeEmail.To, TokenPart1: "x"},
+		{From: challengeEmail.From, To: challengeEmail.To + "\r", TokenPart1: "x"},
+		{From: challengeEmail.From, To: challengeEmail.To, TokenPart1: "x", MessageID: "<a@b>\r\nBcc: victim@example.com"},
+	} {
+		if _, err := BuildEmailReply00Response(bad, "keyauth"); err == nil {
+			t.Errorf("expected line break error for %+v, got none", bad)
+		}
+	}
+}
+
+func TestEmailReply00Solver(t *testing.T) {
+	account := Account{Thumbprint: "thumbprint"}
+	auth := Authorization{Identifier: Identifier{Type: IdentifierTypeEmail, Value: "alexey@example.com"}}
+	chal := Challenge{Type: ChallengeTypeEmailReply00, From: "acme-generator@example.org", Token: "DGyRejmCefe7v4NfDGDKfA"}
+
+	if err := (EmailReply00Solver{}).Present(account, auth, chal); err == nil {
+		t.Fatal("expected error with no transport, got none")
+	}
+	if err := (EmailReply00Solver{Transport: &memoryEmailTransport{}}).Present(account, auth, chal); err == nil {
+		t.Fatal("expected error with no verification, got none")
+	}
+
+	transport := &memoryEmailTransport{inbox: []byte(testChallengeEmail)}
+	verifyErr := EmailReply00Solver{
+		Transport: transport,
+		Verify: func([]byte) error {
+			return errors.New("bad dkim")
+		},
+	}
+	if err := verifyErr.Present(account, auth, chal); err == nil || !strings.Contains(err.Error(), "bad dkim") {
+		t.Fatalf("expected verification error, got: %v", err)
+	}
+	if transport.sent != nil {
+		t.Fatal("response sent for unverified challenge")
+	}
+
+	solver := EmailReply00Solver{
+		Transport: transport,
+		Verify: func([]byte) error {
+			return nil
+		},
+	}
+	if err := solver.Present(account, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(transport.to) != 1 || transport.to[0] != chal.From {
+		t.Fatalf("response sent to %v, expected %s", transport.to, chal.From)
+	}
+	keyAuth := EmailReply00KeyAuthorization("bELa8eY7E2nhP9z5Wa7WoDRqAlPNs8Hw", chal, account.Thumbprint)
+	if !bytes.Contains(transport.sent, []byte(EncodeDNS01KeyAuthorization(keyAuth))) {
+		t.Fatalf("response does not contain key authorization digest: %s", transport.sent)
+	}
+	if err := solver.CleanUp(account, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
-	"crypto/x509/pkix"
 	"encoding/json"
 	"encoding/pem"
 	"flag"
@@ -19,7 +18,6 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/eggsampler/acme/v3"
@@ -87,14 +85,8 @@ func main() {
 	}
 	log.Printf("Account url: %s", account.URL)
 
-	// prepend the .well-known/acme-challenge path to the webroot path
-	webroot = filepath.Join(webroot, ".well-known", "acme-challenge")
-	if _, err := os.Stat(webroot); os.IsNotExist(err) {
-		log.Printf("Making directory path: %s", webroot)
-		if err := os.MkdirAll(webroot, 0755); err != nil {
-			log.Fatalf("Error creating webroot path %q: %v", webroot, err)
-		}
-	}
+	// the webroot solver writes challenge files under the webroot .well-known/acme-challenge path
+	solver := acme.HTTP01WebrootSolver{Root: webroot}
 
 	// collect the comma separated domains into acme identifiers
 	domainList := strings.Split(domains, ",")
@@ -128,12 +120,11 @@ func main() {
 		}
 
 		// create the challenge token file with the key authorization from the challenge
-		tokenFile := filepath.Join(webroot, chal.Token)
-		log.Printf("Creating challenge token file: %s", tokenFile)
-		defer os.Remove(tokenFile)
-		if err := ioutil.WriteFile(tokenFile, []byte(chal.KeyAuthorization), 0644); err != nil {
-			log.Fatalf("Error writing authorization %s challenge file %q: %v", auth.Identifier.Value, tokenFile, err)
+		log.Printf("Creating challenge token file for authorization %s", auth.Identifier.Value)
+		if err := solver.Present(account, auth, chal); err != nil {
+			log.Fatalf("Error writing authorization %s challenge file: %v", auth.Identifier.Value, err)
 		}
+		defer solver.CleanUp(account, auth, chal)
 
 		/*
 			If you wanted to use a DNS-01 challenge you would extract the challenge object,
@@ -172,23 +163,12 @@ func main() {
 		log.Fatalf("Error writing key file %q: %v", keyFile, err)
 	}
 
-	// create the new csr template
+	// create the new csr for the order identifiers
 	log.Printf("Creating csr")
-	tpl := &x509.CertificateRequest{
-		SignatureAlgorithm: x509.ECDSAWithSHA256,
-		PublicKeyAlgorithm: x509.ECDSA,
-		PublicKey:          certKey.Public(),
-		Subject:            pkix.Name{CommonName: domainList[0]},
-		DNSNames:           domainList,
-	}
-	csrDer, err := x509.CreateCertificateRequest(rand.Reader, tpl, certKey)
+	csr, err := acme.NewCSRFromOrder(order, certKey, acme.CSROptCommonName(domainList[0]))
 	if err != nil {
 		log.Fatalf("Error creating certificate request: %v", err)
 	}
-	csr, err := x509.ParseCertificateRequest(csrDer)
-	if err != nil {
-		log.Fatalf("Error parsing certificate request: %v", err)
-	}
 
 	// finalize the order with the acme server given a csr
 	log.Printf("Finalising order: %s", order.URL)
@@ -0,0 +1,224 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// HTTP01ChallengePath is the url path prefix http-01 challenge tokens are served under.
+// See https://tools.ietf.org/html/rfc8555#section-8.3
+const HTTP01ChallengePath = "/.well-known/acme-challenge/"
+
+// Helper function to check a http-01 token is safe to use as a file name or url path segment.
+func checkHTTP01Token(token string) error {
+	if token == "" || token == "." || token == ".." || strings.ContainsAny(token, `/\`) {
+		return fmt.Errorf("acme: invalid http-01 token: %q", token)
+	}
+	return nil
+}
+
+// HTTP01StandaloneSolver is a Solver for http-01 challenges which runs its own http server while challenges are
+// presented, serving only key authorizations from HTTP01ChallengePath. The server is started by the first call to
+// Present and shut down once every presented challenge has been cleaned up.
+type HTTP01StandaloneSolver struct {
+	// Addr is the address the http server listens on.
+	// Default ":80" if not set.
+	Addr string
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+	tokens   map[string]string
+}
+
+// Present starts the http server if not already running and serves the challenge key authorization.
+func (s *HTTP01StandaloneSolver) Present(account Account, auth Authorization, chal Challenge) error {
+	if err := checkHTTP01Token(chal.Token); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server == nil {
+		addr := s.Addr
+		if addr == "" {
+			addr = ":80"
+		}
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("acme: error listening on %s: %v", addr, err)
+		}
+		s.listener = l
+		s.server = &http.Server{Handler: http.HandlerFunc(s.serveHTTP)}
+		go s.server.Serve(l)
+		s.tokens = map[string]string{}
+	}
+
+	s.tokens[chal.Token] = chal.KeyAuthorization
+	return nil
+}
+
+// CleanUp stops serving the challenge key authorization, shutting down the http server if no challenges remain.
+func (s *HTTP01StandaloneSolver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, chal.Token)
+	if len(s.tokens) > 0 || s.server == nil {
+		return nil
+	}
+
+	err := s.server.Close()
+	s.server, s.listener = nil, nil
+	return err
+}
+
+func (s *HTTP01StandaloneSolver) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, HTTP01ChallengePath)
+	if !strings.HasPrefix(r.URL.Path, HTTP01ChallengePath) || checkHTTP01Token(token) != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	keyAuth, ok := s.tokens[token]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write([]byte(keyAuth))
+}
+
+// FileOwner is the numeric user and group ids files are owned by.
+type FileOwner struct {
+	UID int
+	GID int
+}
+
+// HTTP01WebrootSolver is a Solver for http-01 challenges which writes key authorizations into the
+// .well-known/acme-challenge directory of an existing web server root, removing them once cleaned up.
+type HTTP01WebrootSolver struct {
+	// Root is the web server root directory.
+	Root string
+
+	// FileMode of the key authorization files.
+	// Default 0644 if not set.
+	FileMode os.FileMode
+
+	// DirMode of any directories created under the root.
+	// Default 0755 if not set.
+	DirMode os.FileMode
+
+	// Owner of the key authorization files and any directories created, optional.
+	Owner *FileOwner
+}
+
+// Present writes the challenge key authorization to a file named by the challenge token. Tokens containing path
+// separators are refused.
+func (s HTTP01WebrootSolver) Present(account Account, auth Authorization, chal Challenge) error {
+	if s.Root == "" {
+		return errors.New("acme: no webroot provided")
+	}
+	path, err := s.tokenPath(chal.Token)
+	if err != nil {
+		return err
+	}
+
+	fileMode, dirMode := s.FileMode, s.DirMode
+	if fileMode == 0 {
+		fileMode = 0644
+	}
+	if dirMode == 0 {
+		dirMode = 0755
+	}
+
+	if err := s.mkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, []byte(chal.KeyAuthorization), fileMode); err != nil {
+		return fmt.Errorf("acme: error writing challenge file %q: %v", path, err)
+	}
+	return s.setMode(path, fileMode)
+}
+
+// CleanUp removes the challenge key authorization file.
+func (s HTTP01WebrootSolver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	path, err := s.tokenPath(chal.Token)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("acme: error removing challenge file %q: %v", path, err)
+	}
+	return nil
+}
+
+// Helper function to build the key authorization file path for a token.
+func (s HTTP01WebrootSolver) tokenPath(token string) (string, error) {
+	if err := checkHTTP01Token(token); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Root, ".well-known", "acme-challenge", token), nil
+}
+
+// Helper function to create a directory and any missing parents, including the root, like os.MkdirAll. Each
+// directory created is given the requested mode and owner, existing directories are left as is.
+func (s HTTP01WebrootSolver) mkdirAll(dir string, mode os.FileMode) error {
+	var missing []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		fi, err := os.Stat(d)
+		if err == nil {
+			if !fi.IsDir() {
+				return fmt.Errorf("acme: webroot path %q is not a directory", d)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("acme: error checking webroot directory %q: %v", d, err)
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	// create from the top down so each directory can be given the requested mode and owner
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], mode); err != nil && !os.IsExist(err) {
+			return fmt.Errorf("acme: error creating webroot directory %q: %v", missing[i], err)
+		}
+		if err := s.setMode(missing[i], mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Helper function to set the mode, ignoring any umask, and owner of a created file or directory.
+func (s HTTP01WebrootSolver) setMode(path string, mode os.FileMode) error {
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("acme: error setting mode of %q: %v", path, err)
+	}
+	if s.Owner != nil {
+		if err := os.Chown(path, s.Owner.UID, s.Owner.GID); err != nil {
+			return fmt.Errorf("acme: error setting owner of %q: %v", path, err)
+		}
+	}
+	return nil
+}
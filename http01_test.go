@@ -0,0 +1,156 @@
+package acme
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_checkHTTP01Token(t *testing.T) {
+	for _, token := range []string{"", ".", "..", "a/b", `a\b`, "../etc"} {
+		if err := checkHTTP01Token(token); err == nil {
+			t.Errorf("expected error for token %q, got none", token)
+		}
+	}
+	if err := checkHTTP01Token("LoqXcYV8q5ONbJQxbmR7SCTNo3tiAXDfowyjxAjEuX0"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHTTP01StandaloneSolver(t *testing.T) {
+	s := &HTTP01StandaloneSolver{Addr: "127.0.0.1:0"}
+	chal1 := Challenge{Token: "token1", KeyAuthorization: "token1.thumbprint"}
+	chal2 := Challenge{Token: "token2", KeyAuthorization: "token2.thumbprint"}
+
+	if err := s.Present(Account{}, Authorization{}, Challenge{Token: "../x"}); err == nil {
+		t.Fatal("expected error with bad token, got none")
+	}
+	if err := s.Present(Account{}, Authorization{}, chal1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Present(Account{}, Authorization{}, chal2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	base := "http://" + s.listener.Addr().String()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatalf("unexpected error fetching %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	if code, body := get(HTTP01ChallengePath + "token1"); code != http.StatusOK || body != chal1.KeyAuthorization {
+		t.Fatalf("unexpected response: %d %s", code, body)
+	}
+	if code, _ := get(HTTP01ChallengePath + "unknown"); code != http.StatusNotFound {
+		t.Fatalf("expected not found for unknown token, got: %d", code)
+	}
+	if code, _ := get("/token1"); code != http.StatusNotFound {
+		t.Fatalf("expected not found outside challenge path, got: %d", code)
+	}
+
+	if err := s.CleanUp(Account{}, Authorization{}, chal1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code, _ := get(HTTP01ChallengePath + "token1"); code != http.StatusNotFound {
+		t.Fatalf("expected not found for cleaned up token, got: %d", code)
+	}
+
+	if err := s.CleanUp(Account{}, Authorization{}, chal2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.server != nil {
+		t.Fatal("expected server to be shut down")
+	}
+	if _, err := http.Get(base + HTTP01ChallengePath + "token2"); err == nil {
+		t.Fatal("expected error connecting to shut down server, got none")
+	}
+}
+
+func TestHTTP01WebrootSolver(t *testing.T) {
+	root, err := ioutil.TempDir("", "webroot")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	if err := (HTTP01WebrootSolver{}).Present(Account{}, Authorization{}, Challenge{Token: "x"}); err == nil {
+		t.Fatal("expected error with no root, got none")
+	}
+
+	s := HTTP01WebrootSolver{Root: root, FileMode: 0600, DirMode: 0750}
+	if err := s.Present(Account{}, Authorization{}, Challenge{Token: "../escape"}); err == nil {
+		t.Fatal("expected error with path separator in token, got none")
+	}
+
+	chal := Challenge{Token: "token1", KeyAuthorization: "token1.thumbprint"}
+	if err := s.Present(Account{}, Authorization{}, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(root, ".well-known", "acme-challenge", "token1")
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading challenge file: %v", err)
+	}
+	if string(b) != chal.KeyAuthorization {
+		t.Fatalf("unexpected challenge file contents: %s", string(b))
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0600 {
+		t.Fatalf("unexpected file mode: %v", fi.Mode())
+	}
+	if fi, _ := os.Stat(filepath.Dir(path)); fi.Mode().Perm() != 0750 {
+		t.Fatalf("unexpected directory mode: %v", fi.Mode())
+	}
+
+	if err := s.CleanUp(Account{}, Authorization{}, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected challenge file removed, got: %v", err)
+	}
+	if err := s.CleanUp(Account{}, Authorization{}, chal); err != nil {
+		t.Fatalf("unexpected error cleaning up twice: %v", err)
+	}
+}
+
+func TestHTTP01WebrootSolver2(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "webroot")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// a missing root is created along with the challenge directories
+	root := filepath.Join(tmp, "missing", "root")
+	s := HTTP01WebrootSolver{Root: root, DirMode: 0750}
+	chal := Challenge{Token: "token1", KeyAuthorization: "token1.thumbprint"}
+	if err := s.Present(Account{}, Authorization{}, chal); err != nil {
+		t.Fatalf("unexpected error with missing root: %v", err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(root, ".well-known", "acme-challenge", "token1"))
+	if err != nil || string(b) != chal.KeyAuthorization {
+		t.Fatalf("unexpected challenge file: %q %v", b, err)
+	}
+	for _, dir := range []string{filepath.Join(tmp, "missing"), root, filepath.Join(root, ".well-known")} {
+		if fi, err := os.Stat(dir); err != nil || fi.Mode().Perm() != 0750 {
+			t.Fatalf("unexpected directory %s: %v %v", dir, fi, err)
+		}
+	}
+
+	// a root which isn't a directory is refused
+	file := filepath.Join(tmp, "file")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (HTTP01WebrootSolver{Root: file}).Present(Account{}, Authorization{}, chal); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Fatalf("expected not a directory error, got: %v", err)
+	}
+}
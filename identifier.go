@@ -0,0 +1,121 @@
+package acme
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Different possible identifier types used in orders and authorizations.
+// See https://tools.ietf.org/html/rfc8555#section-9.7.7
+const (
+	IdentifierTypeDNS = "dns"
+
+	// See https://tools.ietf.org/html/rfc8738
+	IdentifierTypeIP = "ip"
+
+	// See https://tools.ietf.org/html/rfc8823
+	IdentifierTypeEmail = "email"
+
+	// See https://tools.ietf.org/html/rfc9448
+	IdentifierTypeTNAuthList = "TNAuthList"
+
+	// See https://datatracker.ietf.org/doc/html/draft-ietf-acme-device-attest#section-3
+	IdentifierTypePermanentIdentifier = "permanent-identifier"
+	IdentifierTypeHardwareModule      = "hardware-module"
+)
+
+// NewIdentifier classifies a value as either an ip or dns identifier. IP addresses are normalised, see
+// NewIPIdentifier.
+func NewIdentifier(value string) Identifier {
+	if ip := net.ParseIP(value); ip != nil {
+		return NewIPIdentifier(ip)
+	}
+	return Identifier{Type: IdentifierTypeDNS, Value: value}
+}
+
+// NewIPIdentifier creates an ip identifier from an ip address. IPv4 addresses (including IPv4-mapped IPv6 addresses)
+// are encoded as dotted decimal, and IPv6 addresses as the RFC 5952 canonical text representation.
+// See https://tools.ietf.org/html/rfc8738#section-3
+func NewIPIdentifier(ip net.IP) Identifier {
+	return Identifier{Type: IdentifierTypeIP, Value: ip.String()}
+}
+
+// NormalizeIdentifier returns an identifier in the canonical form expected by acme servers. For ip identifiers the
+// value is parsed and re-encoded, returning an error if it isn't a valid ip address. For dns identifiers the value is
+// lower-cased and any trailing dot is removed. Other identifiers are returned unchanged.
+func NormalizeIdentifier(identifier Identifier) (Identifier, error) {
+	switch identifier.Type {
+	case IdentifierTypeIP:
+		ip := net.ParseIP(identifier.Value)
+		if ip == nil {
+			return identifier, fmt.Errorf("acme: invalid ip identifier: %q", identifier.Value)
+		}
+		return NewIPIdentifier(ip), nil
+
+	case IdentifierTypeDNS:
+		identifier.Value = strings.TrimSuffix(strings.ToLower(identifier.Value), ".")
+		return identifier, nil
+
+	default:
+		return identifier, nil
+	}
+}
+
+// ReverseDNSName returns the reverse DNS name of an ip address, in the in-addr.arpa or ip6.arpa domain, as used for
+// the tls-alpn-01 SNI value of ip identifiers. Returns an empty string if the ip address is invalid.
+// See https://tools.ietf.org/html/rfc8738#section-6
+func ReverseDNSName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", ip4[3], ip4[2], ip4[1], ip4[0])
+	}
+
+	const hexDigits = "0123456789abcdef"
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return ""
+	}
+	b := make([]byte, 0, len(ip16)*4+len("ip6.arpa"))
+	for i := len(ip16) - 1; i >= 0; i-- {
+		b = append(b, hexDigits[ip16[i]&0x0f], '.', hexDigits[ip16[i]>>4], '.')
+	}
+	return string(append(b, "ip6.arpa"...))
+}
+
+// TLSALPN01ServerName returns the TLS server name (SNI) the acme server uses when validating a tls-alpn-01 challenge
+// for an identifier. This is the domain name for dns identifiers, or the reverse DNS name for ip identifiers.
+func TLSALPN01ServerName(identifier Identifier) (string, error) {
+	switch identifier.Type {
+	case IdentifierTypeDNS:
+		return identifier.Value, nil
+	case IdentifierTypeIP:
+		ip := net.ParseIP(identifier.Value)
+		if ip == nil {
+			return "", fmt.Errorf("acme: invalid ip identifier: %q", identifier.Value)
+		}
+		return ReverseDNSName(ip), nil
+	default:
+		return "", fmt.Errorf("acme: unsupported identifier type for tls-alpn-01: %q", identifier.Type)
+	}
+}
+
+// HTTP01ChallengeURL returns the url the acme server requests when validating a http-01 challenge for an identifier,
+// enclosing IPv6 addresses in brackets.
+func HTTP01ChallengeURL(identifier Identifier, token string) (string, error) {
+	host := identifier.Value
+	switch identifier.Type {
+	case IdentifierTypeDNS:
+	case IdentifierTypeIP:
+		ip := net.ParseIP(identifier.Value)
+		if ip == nil {
+			return "", fmt.Errorf("acme: invalid ip identifier: %q", identifier.Value)
+		}
+		host = ip.String()
+		if ip.To4() == nil {
+			host = "[" + host + "]"
+		}
+	default:
+		return "", fmt.Errorf("acme: unsupported identifier type for http-01: %q", identifier.Type)
+	}
+	return "http://" + host + HTTP01ChallengePath + token, nil
+}
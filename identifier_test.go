@@ -0,0 +1,124 @@
+package acme
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNewIdentifier(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Identifier
+	}{
+		{"example.com", Identifier{Type: IdentifierTypeDNS, Value: "example.com"}},
+		{"*.example.com", Identifier{Type: IdentifierTypeDNS, Value: "*.example.com"}},
+		{"192.0.2.1", Identifier{Type: IdentifierTypeIP, Value: "192.0.2.1"}},
+		{"::ffff:192.0.2.1", Identifier{Type: IdentifierTypeIP, Value: "192.0.2.1"}},
+		{"2001:DB8:0:0:0:0:0:1", Identifier{Type: IdentifierTypeIP, Value: "2001:db8::1"}},
+	}
+	for _, ct := range tests {
+		if id := NewIdentifier(ct.value); id != ct.expected {
+			t.Errorf("%s: expected %+v, got %+v", ct.value, ct.expected, id)
+		}
+	}
+}
+
+func TestNormalizeIdentifier(t *testing.T) {
+	tests := []struct {
+		identifier Identifier
+		expected   Identifier
+		hasError   bool
+	}{
+		{
+			identifier: Identifier{Type: IdentifierTypeDNS, Value: "Example.COM."},
+			expected:   Identifier{Type: IdentifierTypeDNS, Value: "example.com"},
+		},
+		{
+			identifier: Identifier{Type: IdentifierTypeIP, Value: "2001:0db8::0001"},
+			expected:   Identifier{Type: IdentifierTypeIP, Value: "2001:db8::1"},
+		},
+		{
+			identifier: Identifier{Type: IdentifierTypeIP, Value: "example.com"},
+			hasError:   true,
+		},
+		{
+			identifier: Identifier{Type: "other", Value: "Value"},
+			expected:   Identifier{Type: "other", Value: "Value"},
+		},
+	}
+	for _, ct := range tests {
+		id, err := NormalizeIdentifier(ct.identifier)
+		if ct.hasError {
+			if err == nil {
+				t.Errorf("%+v: expected error, got none", ct.identifier)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: unexpected error: %v", ct.identifier, err)
+		}
+		if id != ct.expected {
+			t.Errorf("%+v: expected %+v, got %+v", ct.identifier, ct.expected, id)
+		}
+	}
+}
+
+func TestReverseDNSName(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected string
+	}{
+		{"192.0.2.1", "1.2.0.192.in-addr.arpa"},
+		// example from https://tools.ietf.org/html/rfc3596#section-2.5
+		{"4321:0:1:2:3:4:567:89ab", "b.a.9.8.7.6.5.0.4.0.0.0.3.0.0.0.2.0.0.0.1.0.0.0.0.0.0.0.1.2.3.4.ip6.arpa"},
+	}
+	for _, ct := range tests {
+		if name := ReverseDNSName(net.ParseIP(ct.ip)); name != ct.expected {
+			t.Errorf("%s: expected %s, got %s", ct.ip, ct.expected, name)
+		}
+	}
+
+	for _, ip := range []net.IP{nil, net.ParseIP("not an ip"), {192, 0, 2}} {
+		if name := ReverseDNSName(ip); name != "" {
+			t.Errorf("%v: expected empty name for invalid ip, got %s", []byte(ip), name)
+		}
+	}
+}
+
+func TestTLSALPN01ServerName(t *testing.T) {
+	if name, err := TLSALPN01ServerName(Identifier{Type: IdentifierTypeDNS, Value: "example.com"}); err != nil || name != "example.com" {
+		t.Fatalf("unexpected dns server name %q: %v", name, err)
+	}
+	if name, err := TLSALPN01ServerName(Identifier{Type: IdentifierTypeIP, Value: "192.0.2.1"}); err != nil || name != "1.2.0.192.in-addr.arpa" {
+		t.Fatalf("unexpected ip server name %q: %v", name, err)
+	}
+	if _, err := TLSALPN01ServerName(Identifier{Type: IdentifierTypeIP, Value: "bad"}); err == nil {
+		t.Fatal("expected error, got none")
+	}
+	if _, err := TLSALPN01ServerName(Identifier{Type: "other", Value: "x"}); err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
+
+func TestHTTP01ChallengeURL(t *testing.T) {
+	tests := []struct {
+		identifier Identifier
+		expected   string
+		hasError   bool
+	}{
+		{Identifier{Type: IdentifierTypeDNS, Value: "example.com"}, "http://example.com/.well-known/acme-challenge/token", false},
+		{Identifier{Type: IdentifierTypeIP, Value: "192.0.2.1"}, "http://192.0.2.1/.well-known/acme-challenge/token", false},
+		{Identifier{Type: IdentifierTypeIP, Value: "2001:db8::1"}, "http://[2001:db8::1]/.well-known/acme-challenge/token", false},
+		{Identifier{Type: IdentifierTypeIP, Value: "bad"}, "", true},
+		{Identifier{Type: "other", Value: "x"}, "", true},
+	}
+	for _, ct := range tests {
+		u, err := HTTP01ChallengeURL(ct.identifier, "token")
+		if ct.hasError != (err != nil) {
+			t.Errorf("%+v: expected error %t, got: %v", ct.identifier, ct.hasError, err)
+		}
+		if u != ct.expected {
+			t.Errorf("%+v: expected %s, got %s", ct.identifier, ct.expected, u)
+		}
+	}
+}
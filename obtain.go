@@ -0,0 +1,296 @@
+package acme
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Key types used for generating a certificate private key in ObtainCertificate.
+const (
+	KeyTypeECDSAP256 = "ecdsa-p256"
+	KeyTypeECDSAP384 = "ecdsa-p384"
+	KeyTypeRSA2048   = "rsa-2048"
+	KeyTypeRSA4096   = "rsa-4096"
+)
+
+// CertificateRequest describes a certificate to be obtained with ObtainCertificate.
+type CertificateRequest struct {
+	// Identifiers to be included in the certificate.
+	Identifiers []Identifier
+
+	// PrivateKey is an existing certificate private key.
+	// If nil, a new private key is generated according to KeyType.
+	PrivateKey crypto.Signer
+
+	// KeyType of the private key to generate if PrivateKey is nil.
+	// Default KeyTypeECDSAP256 if not set.
+	KeyType string
+
+	// Profile requested for the order, optional.
+	Profile string
+
+	// NotBefore and NotAfter request the validity period of the certificate, optional.
+	// If granted by the acme server, the issued certificate is checked against the order.
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// PreferredChain is the common name of the issuer at the top of the preferred certificate chain.
+	// If no chains match, or not set, the default chain is used.
+	PreferredChain string
+
+	// MustStaple requests the OCSP Must-Staple extension in the certificate.
+	MustStaple bool
+
+	// Replaces is the certificate being replaced, optional.
+	// See ReplacementOrder.
+	Replaces *x509.Certificate
+
+	// Solvers used for fulfilling challenges, keyed by challenge type.
+	// For each authorization, the first challenge offered by the acme server with a matching solver is used.
+	// If empty, the client solver registry is used, see WithSolverRegistry.
+	Solvers map[string]Solver
+
+	// KeyRef is an opaque reference to the certificate private key, eg a file path, stored in the order state.
+	KeyRef string
+
+	// SaveState is called whenever the order state changes, eg to persist it so the order can be continued with
+	// ResumeCertificate after a restart. Optional, an error aborts the issuance.
+	SaveState func(OrderState) error
+}
+
+func (req CertificateRequest) saveState(state OrderState) error {
+	if req.SaveState == nil {
+		return nil
+	}
+	if err := req.SaveState(state); err != nil {
+		return fmt.Errorf("acme: error saving order state: %v", err)
+	}
+	return nil
+}
+
+// CertificateResult contains the outcome of a successful ObtainCertificate.
+type CertificateResult struct {
+	// PrivateKey for the certificate, either provided in the request or generated.
+	PrivateKey crypto.Signer
+
+	// Certificates is the issued certificate chain, leaf first.
+	Certificates []*x509.Certificate
+
+	// Order is the final, valid, order.
+	Order Order
+}
+
+// ObtainCertificate performs the entire certificate issuance process: creating a new order, fulfilling each
+// pending authorization with the provided solvers, finalizing the order and fetching the certificate chain.
+func (c Client) ObtainCertificate(account Account, req CertificateRequest) (CertificateResult, error) {
+	result := CertificateResult{}
+
+	if len(req.Identifiers) == 0 {
+		return result, errors.New("acme: no identifiers provided")
+	}
+	if len(req.Solvers) == 0 && c.solvers == nil {
+		return result, errors.New("acme: no challenge solvers provided")
+	}
+
+	result.PrivateKey = req.PrivateKey
+	if result.PrivateKey == nil {
+		var err error
+		result.PrivateKey, err = generatePrivateKey(req.KeyType)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	order, err := c.ReplacementOrderExtension(account, req.Replaces, req.Identifiers, OrderExtension{
+		Profile:   req.Profile,
+		NotBefore: req.NotBefore,
+		NotAfter:  req.NotAfter,
+	})
+	if err != nil {
+		return result, fmt.Errorf("acme: error creating new order: %v", err)
+	}
+
+	state := OrderState{
+		DirectoryURL: c.dir.URL,
+		AccountURL:   account.URL,
+		OrderURL:     order.URL,
+		Identifiers:  order.Identifiers,
+		KeyRef:       req.KeyRef,
+	}
+	if err := req.saveState(state); err != nil {
+		return result, err
+	}
+
+	return c.completeOrder(account, order, state, req, result)
+}
+
+// Helper function to continue an order from whichever step is incomplete according to the order status, through to
+// fetching the issued certificate chain.
+func (c Client) completeOrder(account Account, order Order, state OrderState, req CertificateRequest, result CertificateResult) (CertificateResult, error) {
+	result.Order = order
+
+	var err error
+	switch order.Status {
+	case "pending":
+		for _, authURL := range order.Authorizations {
+			if err := c.completeAuthorization(account, authURL, &state, req); err != nil {
+				return result, err
+			}
+		}
+		fallthrough
+
+	case "ready":
+		var opts []CSROptionFunc
+		if req.MustStaple {
+			opts = append(opts, CSROptMustStaple())
+		}
+		csr, err := createCSR(order.Identifiers, result.PrivateKey, opts...)
+		if err != nil {
+			return result, err
+		}
+		if err := CheckCSR(order, csr); err != nil {
+			return result, err
+		}
+
+		order, err = c.FinalizeOrder(account, order, csr)
+		result.Order = order
+		if err != nil {
+			return result, fmt.Errorf("acme: error finalizing order: %v", err)
+		}
+
+	case "processing":
+		order, err = c.WaitForOrder(account, order.URL, 0)
+		result.Order = order
+		if err != nil {
+			return result, fmt.Errorf("acme: error waiting for order: %v", err)
+		}
+
+	case "valid":
+
+	default:
+		_, err := checkFinalizedOrderStatus(order)
+		return result, err
+	}
+
+	result.Certificates, err = c.fetchPreferredChain(account, order.Certificate, req.PreferredChain)
+	if err != nil {
+		return result, fmt.Errorf("acme: error fetching certificates: %v", err)
+	}
+	if len(result.Certificates) == 0 {
+		return result, errors.New("acme: no certificates returned")
+	}
+
+	if err := CheckCertificateValidity(order, result.Certificates[0]); err != nil {
+		return result, err
+	}
+
+	if profile, ok := c.Profile(order.Profile); ok {
+		if err := CheckCertificateProfile(result.Certificates[0], profile); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// Helper function to fulfil a single authorization, preferring any challenge previously chosen in the order state.
+func (c Client) completeAuthorization(account Account, authURL string, state *OrderState, req CertificateRequest) error {
+	auth, err := c.FetchAuthorization(account, authURL)
+	if err != nil {
+		return fmt.Errorf("acme: error fetching authorization %q: %v", authURL, err)
+	}
+
+	if auth.Status == "valid" {
+		return nil
+	}
+	if auth.Status != "pending" {
+		return fmt.Errorf("acme: unexpected authorization status %q for %s", auth.Status, auth.Identifier.Value)
+	}
+
+	solvers := req.Solvers
+	if len(solvers) == 0 && c.solvers != nil {
+		solvers = c.solvers.SolversFor(auth)
+	}
+
+	chal, solver, ok := pickSolver(auth, solvers, state.Challenges[authURL].Type)
+	if !ok {
+		return fmt.Errorf("acme: no solver for authorization %s challenges: %v", auth.Identifier.Value, auth.ChallengeTypes)
+	}
+
+	if state.Challenges == nil {
+		state.Challenges = map[string]OrderStateChallenge{}
+	}
+	state.Challenges[authURL] = OrderStateChallenge{Type: chal.Type, URL: chal.URL}
+	if err := req.saveState(*state); err != nil {
+		return err
+	}
+
+	_, err = c.solveChallenge(account, auth, chal, solver)
+	return err
+}
+
+// Helper function to fetch the certificate chain whose topmost certificate was issued by the preferred issuer,
+// falling back to the default chain.
+func (c Client) fetchPreferredChain(account Account, certificateURL, preferredChain string) ([]*x509.Certificate, error) {
+	if preferredChain == "" {
+		return c.FetchCertificates(account, certificateURL)
+	}
+
+	chains, err := c.FetchAllCertificates(account, certificateURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, chain := range chains {
+		if len(chain) > 0 && chain[len(chain)-1].Issuer.CommonName == preferredChain {
+			return chain, nil
+		}
+	}
+
+	return chains[certificateURL], nil
+}
+
+// Helper function to generate a new private key of the given key type.
+func generatePrivateKey(keyType string) (crypto.Signer, error) {
+	var key crypto.Signer
+	var err error
+	switch keyType {
+	case "", KeyTypeECDSAP256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyTypeECDSAP384:
+		key, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case KeyTypeRSA2048:
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case KeyTypeRSA4096:
+		key, err = rsa.GenerateKey(rand.Reader, 4096)
+	default:
+		return nil, fmt.Errorf("acme: unknown key type: %q", keyType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("acme: error generating private key: %v", err)
+	}
+	return key, nil
+}
+
+// Helper function to create a certificate signing request for a list of identifiers, using the first dns identifier
+// as the common name if short enough.
+func createCSR(identifiers []Identifier, key crypto.Signer, options ...CSROptionFunc) (*x509.CertificateRequest, error) {
+	var opts []CSROptionFunc
+	for _, id := range identifiers {
+		if id.Type != IdentifierTypeDNS {
+			continue
+		}
+		if len(id.Value) <= 64 {
+			opts = append(opts, CSROptCommonName(id.Value))
+		}
+		break
+	}
+	return NewCSR(identifiers, key, append(opts, options...)...)
+}
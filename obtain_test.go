@@ -0,0 +1,145 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// testSolver fulfils challenges using challtestsrv, counting calls to Present and CleanUp
+type testSolver struct {
+	presentErr error
+	presented  int
+	cleaned    int
+}
+
+func (s *testSolver) Present(account Account, auth Authorization, chal Challenge) error {
+	s.presented++
+	if s.presentErr != nil {
+		return s.presentErr
+	}
+	preChallenge(account, auth, chal)
+	return nil
+}
+
+func (s *testSolver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	s.cleaned++
+	if s.presentErr != nil {
+		return nil
+	}
+	postChallenge(account, auth, chal)
+	return nil
+}
+
+func TestClient_ObtainCertificate(t *testing.T) {
+	account := makeAccount(t)
+	solver := &testSolver{}
+	domains := []string{randString() + ".com", randString() + ".com"}
+
+	result, err := testClient.ObtainCertificate(account, CertificateRequest{
+		Identifiers: []Identifier{{Type: "dns", Value: domains[0]}, {Type: "dns", Value: domains[1]}},
+		Solvers:     map[string]Solver{ChallengeTypeHTTP01: solver},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error obtaining certificate: %v", err)
+	}
+	if result.Order.Status != "valid" {
+		t.Fatalf("expected valid order, got: %s", result.Order.Status)
+	}
+	if len(result.Certificates) == 0 {
+		t.Fatal("no certs returned")
+	}
+	for _, d := range domains {
+		if err := result.Certificates[0].VerifyHostname(d); err != nil {
+			t.Fatalf("cert not verified for %s: %v", d, err)
+		}
+	}
+	if _, ok := result.PrivateKey.(*ecdsa.PrivateKey); !ok {
+		t.Fatalf("expected generated ecdsa key, got: %T", result.PrivateKey)
+	}
+	if solver.presented != 2 || solver.cleaned != 2 {
+		t.Fatalf("expected 2 presented and cleaned challenges, got: %d, %d", solver.presented, solver.cleaned)
+	}
+}
+
+func TestClient_ObtainCertificate2(t *testing.T) {
+	account := makeAccount(t)
+
+	if _, err := testClient.ObtainCertificate(account, CertificateRequest{}); err == nil {
+		t.Fatal("expected error with no identifiers, got none")
+	}
+
+	ids := []Identifier{{Type: "dns", Value: randString() + ".com"}}
+	if _, err := testClient.ObtainCertificate(account, CertificateRequest{Identifiers: ids}); err == nil {
+		t.Fatal("expected error with no solvers, got none")
+	}
+
+	if _, err := testClient.ObtainCertificate(account, CertificateRequest{
+		Identifiers: ids,
+		Solvers:     map[string]Solver{"fake-01": &testSolver{}},
+	}); err == nil || !strings.Contains(err.Error(), "no solver") {
+		t.Fatalf("expected no solver error, got: %v", err)
+	}
+
+	solver := &testSolver{presentErr: errors.New("ALWAYS ERRORS")}
+	_, err := testClient.ObtainCertificate(account, CertificateRequest{
+		Identifiers: ids,
+		Solvers:     map[string]Solver{ChallengeTypeHTTP01: solver},
+	})
+	if err == nil || !strings.Contains(err.Error(), "ALWAYS") {
+		t.Fatalf("expected present error, got: %v", err)
+	}
+	if solver.cleaned != 1 {
+		t.Fatalf("expected clean up after failed present, got: %d", solver.cleaned)
+	}
+}
+
+func Test_generatePrivateKey(t *testing.T) {
+	if _, err := generatePrivateKey("bad"); err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	key, err := generatePrivateKey(KeyTypeRSA2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := key.(*rsa.PrivateKey); !ok {
+		t.Fatalf("expected rsa key, got: %T", key)
+	}
+
+	key, err = generatePrivateKey(KeyTypeECDSAP384)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if k, ok := key.(*ecdsa.PrivateKey); !ok || k.Curve.Params().BitSize != 384 {
+		t.Fatalf("expected p384 key, got: %T", key)
+	}
+}
+
+func Test_createCSR(t *testing.T) {
+	key := makePrivateKey(t)
+
+	if _, err := createCSR([]Identifier{{Type: "bad", Value: "x"}}, key); err == nil {
+		t.Fatal("expected error, got none")
+	}
+	if _, err := createCSR([]Identifier{{Type: "ip", Value: "not an ip"}}, key); err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	csr, err := createCSR([]Identifier{{Type: "dns", Value: "example.com"}, {Type: "ip", Value: "192.0.2.1"}}, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if csr.Subject.CommonName != "example.com" {
+		t.Fatalf("unexpected common name: %q", csr.Subject.CommonName)
+	}
+	if len(csr.DNSNames) != 1 || csr.DNSNames[0] != "example.com" {
+		t.Fatalf("unexpected dns names: %v", csr.DNSNames)
+	}
+	if len(csr.IPAddresses) != 1 || !csr.IPAddresses[0].Equal(net.ParseIP("192.0.2.1")) {
+		t.Fatalf("unexpected ip addresses: %v", csr.IPAddresses)
+	}
+}
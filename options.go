@@ -5,6 +5,7 @@ import (
 	"crypto/hmac"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -87,6 +88,41 @@ func WithRootCerts(pool *x509.CertPool) OptionFunc {
 	}
 }
 
+// WithDefaultProfile sets the profile requested in new orders which don't otherwise specify a profile
+func WithDefaultProfile(profile string) OptionFunc {
+	return func(client *Client) error {
+		client.defaultProfile = profile
+		return nil
+	}
+}
+
+// WithSolverRegistry sets the challenge solvers used by SolveAuthorization, and by ObtainCertificate if the request
+// doesn't provide any solvers
+func WithSolverRegistry(registry *SolverRegistry) OptionFunc {
+	return func(client *Client) error {
+		if registry == nil {
+			return errors.New("solver registry must not be nil")
+		}
+		client.solvers = registry
+		return nil
+	}
+}
+
+// WithProfileLifetime sets the expected lifetime of certificates issued under a profile, used by
+// CheckCertificateProfile. Profile lifetimes aren't advertised by acme servers so must be provided here.
+func WithProfileLifetime(profile string, lifetime time.Duration) OptionFunc {
+	return func(client *Client) error {
+		if lifetime <= 0 {
+			return errors.New("lifetime must be > 0")
+		}
+		if client.profileLifetimes == nil {
+			client.profileLifetimes = map[string]time.Duration{}
+		}
+		client.profileLifetimes[profile] = lifetime
+		return nil
+	}
+}
+
 // NewAccountOptionFunc function prototype for passing options to NewClient
 type NewAccountOptionFunc func(crypto.Signer, *Account, *NewAccountRequest, Client) error
 
@@ -171,3 +207,82 @@ func NewAcctOptExternalAccountBinding(binding ExternalAccountBinding) NewAccount
 		return nil
 	}
 }
+
+// UpdateAccountOptionFunc function prototype for passing options to UpdateAccountOptions
+type UpdateAccountOptionFunc func(*Account, *UpdateAccountRequest, Client) error
+
+// UpdateAcctOptWithContacts replaces the account contacts with the provided contacts
+func UpdateAcctOptWithContacts(contacts ...string) UpdateAccountOptionFunc {
+	return func(account *Account, request *UpdateAccountRequest, client Client) error {
+		if len(contacts) == 0 {
+			return errors.New("acme: UpdateAcctOptWithContacts has no contacts, use UpdateAcctOptClearContacts")
+		}
+		request.Contact = contacts
+		return nil
+	}
+}
+
+// UpdateAcctOptClearContacts removes all contacts from the account
+func UpdateAcctOptClearContacts() UpdateAccountOptionFunc {
+	return func(account *Account, request *UpdateAccountRequest, client Client) error {
+		request.Contact = []string{}
+		return nil
+	}
+}
+
+// UpdateAcctOptAgreeTOS sets the account update request as agreeing to the terms of service, eg after the terms of
+// service have changed
+func UpdateAcctOptAgreeTOS() UpdateAccountOptionFunc {
+	return func(account *Account, request *UpdateAccountRequest, client Client) error {
+		request.TermsOfServiceAgreed = true
+		return nil
+	}
+}
+
+// UpdateAcctOptExtension adds a CA-specific field to the account update request
+func UpdateAcctOptExtension(field string, value interface{}) UpdateAccountOptionFunc {
+	return func(account *Account, request *UpdateAccountRequest, client Client) error {
+		switch field {
+		case "":
+			return errors.New("acme: UpdateAcctOptExtension has no field name")
+		case "contact", "termsOfServiceAgreed", "status":
+			return fmt.Errorf("acme: UpdateAcctOptExtension cannot set reserved field %q", field)
+		}
+		if request.Extensions == nil {
+			request.Extensions = map[string]interface{}{}
+		}
+		request.Extensions[field] = value
+		return nil
+	}
+}
+
+// CSROptionFunc function prototype for passing options to NewCSR
+type CSROptionFunc func(*x509.CertificateRequest) error
+
+// CSROptCommonName sets the subject common name of the certificate request, which must be one of the dns or ip
+// identifiers and no longer than 64 characters
+func CSROptCommonName(commonName string) CSROptionFunc {
+	return func(tpl *x509.CertificateRequest) error {
+		if len(commonName) > 64 {
+			return fmt.Errorf("acme: common name longer than 64 characters: %q", commonName)
+		}
+		tpl.Subject.CommonName = commonName
+		return nil
+	}
+}
+
+// CSROptMustStaple adds the TLS feature extension requesting OCSP Must-Staple to the certificate request
+func CSROptMustStaple() CSROptionFunc {
+	return func(tpl *x509.CertificateRequest) error {
+		tpl.ExtraExtensions = append(tpl.ExtraExtensions, pkix.Extension{Id: oidTLSFeature, Value: tlsFeatureStatusRequest})
+		return nil
+	}
+}
+
+// CSROptSignatureAlgorithm overrides the signature algorithm chosen for the private key type
+func CSROptSignatureAlgorithm(algorithm x509.SignatureAlgorithm) CSROptionFunc {
+	return func(tpl *x509.CertificateRequest) error {
+		tpl.SignatureAlgorithm = algorithm
+		return nil
+	}
+}
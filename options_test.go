@@ -221,3 +221,90 @@ func TestNewAcctOptExternalAccountBinding(t *testing.T) {
 		}
 	}
 }
+
+func TestUpdateAcctOptWithContacts(t *testing.T) {
+	r := UpdateAccountRequest{}
+	if err := UpdateAcctOptWithContacts()(nil, &r, Client{}); err == nil {
+		t.Fatal("expected error, got none")
+	}
+	if err := UpdateAcctOptWithContacts("hello")(nil, &r, Client{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(r.Contact) != 1 || r.Contact[0] != "hello" {
+		t.Fatalf(`expected contact "hello" got: %v`, r.Contact)
+	}
+}
+
+func TestUpdateAcctOptClearContacts(t *testing.T) {
+	r := UpdateAccountRequest{}
+	if err := UpdateAcctOptClearContacts()(nil, &r, Client{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if r.Contact == nil || len(r.Contact) != 0 {
+		t.Fatalf("expected empty contacts, got: %#v", r.Contact)
+	}
+}
+
+func TestUpdateAcctOptAgreeTOS(t *testing.T) {
+	r := UpdateAccountRequest{}
+	if err := UpdateAcctOptAgreeTOS()(nil, &r, Client{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !r.TermsOfServiceAgreed {
+		t.Fatal("TermsOfServiceAgreed not set")
+	}
+}
+
+func TestUpdateAcctOptExtension(t *testing.T) {
+	r := UpdateAccountRequest{}
+	for _, field := range []string{"", "contact", "termsOfServiceAgreed", "status"} {
+		if err := UpdateAcctOptExtension(field, "x")(nil, &r, Client{}); err == nil {
+			t.Fatalf("field %q expected error, got none", field)
+		}
+	}
+	if err := UpdateAcctOptExtension("hello", "world")(nil, &r, Client{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if r.Extensions["hello"] != "world" {
+		t.Fatalf("extension not set, got: %v", r.Extensions)
+	}
+}
+
+func TestWithDefaultProfile(t *testing.T) {
+	acmeClient := Client{}
+	opt := WithDefaultProfile("shortlived")
+	if err := opt(&acmeClient); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if acmeClient.DefaultProfile() != "shortlived" {
+		t.Fatalf("default profile not set, got: %q", acmeClient.DefaultProfile())
+	}
+}
+
+func TestWithProfileLifetime(t *testing.T) {
+	acmeClient := Client{}
+	if err := WithProfileLifetime("shortlived", 0)(&acmeClient); err == nil {
+		t.Fatal("expected error, got none")
+	}
+	lifetime := 160 * time.Hour
+	if err := WithProfileLifetime("shortlived", lifetime)(&acmeClient); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if acmeClient.profileLifetimes["shortlived"] != lifetime {
+		t.Fatalf("profile lifetime not set, expected %v, got: %v", lifetime, acmeClient.profileLifetimes)
+	}
+}
+
+func TestWithSolverRegistry(t *testing.T) {
+	acmeClient := Client{}
+	if err := WithSolverRegistry(nil)(&acmeClient); err == nil {
+		t.Fatal("expected error, got none")
+	}
+	registry := NewSolverRegistry()
+	if err := WithSolverRegistry(registry)(&acmeClient); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if acmeClient.solvers != registry {
+		t.Fatal("solver registry not set")
+	}
+}
@@ -5,12 +5,27 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 )
 
+// OrderExtension contains optional fields to be included in a new order request.
 type OrderExtension struct {
 	Profile string
+
+	// NotBefore and NotAfter request the validity period of the certificate, optional.
+	// See https://tools.ietf.org/html/rfc8555#section-7.4
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// AutoRenewal requests a STAR order, optional. Can't be used with NotBefore or NotAfter.
+	// See https://tools.ietf.org/html/rfc8739#section-3.1.1
+	AutoRenewal *AutoRenewal
+
+	// Delegation is the url of the delegation configuration for a delegated order, optional.
+	// See https://tools.ietf.org/html/rfc9115#section-2.3.2
+	Delegation string
 }
 
 // NewOrder initiates a new order for a new certificate. This method does not use ACME Renewal Info.
@@ -22,7 +37,22 @@ func (c Client) NewOrder(account Account, identifiers []Identifier) (Order, erro
 func (c Client) NewOrderDomains(account Account, domains ...string) (Order, error) {
 	var identifiers []Identifier
 	for _, d := range domains {
-		identifiers = append(identifiers, Identifier{Type: "dns", Value: d})
+		identifiers = append(identifiers, Identifier{Type: IdentifierTypeDNS, Value: d})
+	}
+	return c.ReplacementOrder(account, nil, identifiers)
+}
+
+// NewOrderIPs takes a list of ip addresses for a new certificate, creating normalised ip identifiers. Essentially a
+// helper function.
+// See https://tools.ietf.org/html/rfc8738
+func (c Client) NewOrderIPs(account Account, ips ...string) (Order, error) {
+	var identifiers []Identifier
+	for _, v := range ips {
+		ip := net.ParseIP(v)
+		if ip == nil {
+			return Order{}, fmt.Errorf("acme: invalid ip address: %q", v)
+		}
+		identifiers = append(identifiers, NewIPIdentifier(ip))
 	}
 	return c.ReplacementOrder(account, nil, identifiers)
 }
@@ -44,6 +74,13 @@ func (c Client) ReplacementOrder(account Account, oldCert *x509.Certificate, ide
 	return c.ReplacementOrderExtension(account, oldCert, identifiers, OrderExtension{})
 }
 
+// ReplacementOrderFromOrder initiates a replacement order for a certificate issued from an existing order, carrying
+// forward the identifiers and profile of the existing order.
+// See ReplacementOrder
+func (c Client) ReplacementOrderFromOrder(account Account, oldOrder Order, oldCert *x509.Certificate) (Order, error) {
+	return c.ReplacementOrderExtension(account, oldCert, oldOrder.Identifiers, OrderExtension{Profile: oldOrder.Profile})
+}
+
 // ReplacementOrderExtension takes a struct providing any extensions onto the order
 func (c Client) ReplacementOrderExtension(account Account, oldCert *x509.Certificate, identifiers []Identifier, ext OrderExtension) (Order, error) {
 	// If an old cert being replaced is present and the acme directory doesn't list a RenewalInfo endpoint,
@@ -57,13 +94,42 @@ func (c Client) ReplacementOrderExtension(account Account, oldCert *x509.Certifi
 	newOrderReq := struct {
 		Identifiers []Identifier `json:"identifiers"`
 		Replaces    string       `json:"replaces,omitempty"`
-		Profile     string       `json:"Profile,omitempty"`
+		Profile     string       `json:"profile,omitempty"`
+		NotBefore   string       `json:"notBefore,omitempty"`
+		NotAfter    string       `json:"notAfter,omitempty"`
+		AutoRenewal interface{}  `json:"auto-renewal,omitempty"`
+		Delegation  string       `json:"delegation,omitempty"`
 	}{
 		Identifiers: identifiers,
+		Delegation:  ext.Delegation,
 	}
 
 	newOrderResp := Order{}
 
+	if err := checkOrderValidity(ext.NotBefore, ext.NotAfter); err != nil {
+		return Order{}, err
+	}
+	if !ext.NotBefore.IsZero() {
+		newOrderReq.NotBefore = ext.NotBefore.UTC().Format(time.RFC3339)
+	}
+	if !ext.NotAfter.IsZero() {
+		newOrderReq.NotAfter = ext.NotAfter.UTC().Format(time.RFC3339)
+	}
+
+	if ext.AutoRenewal != nil {
+		if !ext.NotBefore.IsZero() || !ext.NotAfter.IsZero() {
+			return Order{}, errors.New("acme: notBefore and notAfter can't be requested with auto-renewal")
+		}
+		autoRenewal, err := c.autoRenewalRequest(*ext.AutoRenewal)
+		if err != nil {
+			return Order{}, err
+		}
+		newOrderReq.AutoRenewal = autoRenewal
+	}
+
+	if ext.Profile == "" {
+		ext.Profile = c.defaultProfile
+	}
 	if ext.Profile != "" {
 		_, ok := c.Directory().Meta.Profiles[ext.Profile]
 		if !ok {
@@ -94,6 +160,41 @@ func (c Client) ReplacementOrderExtension(account Account, oldCert *x509.Certifi
 	return newOrderResp, nil
 }
 
+// Helper function to check a requested certificate validity period is sensible.
+func checkOrderValidity(notBefore, notAfter time.Time) error {
+	if !notAfter.IsZero() && notAfter.Before(time.Now()) {
+		return fmt.Errorf("acme: requested notAfter is in the past: %v", notAfter)
+	}
+	if !notBefore.IsZero() && !notAfter.IsZero() && !notAfter.After(notBefore) {
+		return fmt.Errorf("acme: requested notAfter (%v) is not after notBefore (%v)", notAfter, notBefore)
+	}
+	return nil
+}
+
+// CheckCertificateValidity checks the validity period of an issued certificate matches the notBefore and notAfter
+// granted in the order, if present. A difference of up to one second is allowed, as some CAs treat notAfter as
+// inclusive.
+func CheckCertificateValidity(order Order, cert *x509.Certificate) error {
+	if cert == nil {
+		return errors.New("acme: no certificate provided")
+	}
+	if !order.NotBefore.IsZero() && absDuration(cert.NotBefore.Sub(order.NotBefore)) > time.Second {
+		return fmt.Errorf("acme: certificate notBefore %v does not match order notBefore %v", cert.NotBefore, order.NotBefore)
+	}
+	if !order.NotAfter.IsZero() && absDuration(cert.NotAfter.Sub(order.NotAfter)) > time.Second {
+		return fmt.Errorf("acme: certificate notAfter %v does not match order notAfter %v", cert.NotAfter, order.NotAfter)
+	}
+	return nil
+}
+
+// Helper function to return the absolute value of a duration.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
 // FetchOrder fetches an existing order given an order url.
 func (c Client) FetchOrder(account Account, orderURL string) (Order, error) {
 	orderResp := Order{
@@ -139,6 +240,11 @@ func checkFinalizedOrderStatus(order Order) (bool, error) {
 		//      certificate.
 		return true, nil
 
+	case "canceled":
+		// "canceled": The STAR order has been canceled by the client, no
+		//      further certificates will be issued.
+		return true, errors.New("acme: order has been canceled")
+
 	default:
 		return true, fmt.Errorf("acme: unknown order status: %s", order.Status)
 	}
@@ -146,46 +252,34 @@ func checkFinalizedOrderStatus(order Order) (bool, error) {
 
 // FinalizeOrder indicates to the acme server that the client considers an order complete and "finalizes" it.
 // If the server believes the authorizations have been filled successfully, a certificate should then be available.
-// This function assumes that the order status is "ready".
+// This function assumes that the order status is "ready", and that the csr matches the order identifiers. The csr
+// is not checked, use CheckCSR before finalizing to avoid the acme server rejecting the order as invalid.
+// This function blocks until the order is valid or the poll timeout expires, to avoid blocking use
+// SubmitFinalizeOrder and WaitForOrder instead.
 func (c Client) FinalizeOrder(account Account, order Order, csr *x509.CertificateRequest) (Order, error) {
-	finaliseReq := struct {
-		Csr string `json:"csr"`
-	}{
-		Csr: base64.RawURLEncoding.EncodeToString(csr.Raw),
-	}
-
-	resp, err := c.post(order.Finalize, account.URL, account.PrivateKey, finaliseReq, &order, http.StatusOK)
-	if err != nil {
+	order, err := c.SubmitFinalizeOrder(account, order, csr)
+	if err != nil || order.Status != "processing" {
 		return order, err
 	}
 
-	updateOrder := func(resp *http.Response) (bool, error) {
+	fetchOrder := func() (bool, error) {
+		resp, err := c.post(order.URL, account.URL, account.PrivateKey, "", &order, http.StatusOK)
+		if err != nil {
+			return false, nil
+		}
+
 		if finished, err := checkFinalizedOrderStatus(order); finished {
 			return true, err
 		}
 
-		retryAfter, err := parseRetryAfter(resp.Header.Get("Retry-After"))
+		order.RetryAfter, err = parseRetryAfter(resp.Header.Get("Retry-After"))
 		if err != nil {
 			return false, fmt.Errorf("acme: error parsing retry-after header: %v", err)
 		}
-		order.RetryAfter = retryAfter
 
 		return false, nil
 	}
 
-	if finished, err := updateOrder(resp); finished || err != nil {
-		return order, err
-	}
-
-	fetchOrder := func() (bool, error) {
-		resp, err := c.post(order.URL, account.URL, account.PrivateKey, "", &order, http.StatusOK)
-		if err != nil {
-			return false, nil
-		}
-
-		return updateOrder(resp)
-	}
-
 	if !c.IgnoreRetryAfter && !order.RetryAfter.IsZero() {
 		_, pollTimeout := c.getPollingDurations()
 		end := time.Now().Add(pollTimeout)
@@ -225,5 +319,90 @@ func (c Client) FinalizeOrder(account Account, order Order, csr *x509.Certificat
 		}
 	}
 
-	return order, err
+	return order, nil
+}
+
+// SubmitFinalizeOrder submits a finalization request for an order without waiting for the certificate to be issued.
+// The returned order is either "valid", or "processing" with the RetryAfter field indicating when the order should be
+// fetched again, eg using WaitForOrder.
+// This function assumes that the order status is "ready".
+func (c Client) SubmitFinalizeOrder(account Account, order Order, csr *x509.CertificateRequest) (Order, error) {
+	finaliseReq := struct {
+		Csr string `json:"csr"`
+	}{
+		Csr: base64.RawURLEncoding.EncodeToString(csr.Raw),
+	}
+
+	resp, err := c.post(order.Finalize, account.URL, account.PrivateKey, finaliseReq, &order, http.StatusOK)
+	if err != nil {
+		return order, err
+	}
+
+	if finished, err := checkFinalizedOrderStatus(order); finished {
+		return order, err
+	}
+
+	order.RetryAfter, err = parseRetryAfter(resp.Header.Get("Retry-After"))
+	if err != nil {
+		return order, fmt.Errorf("acme: error parsing retry-after header: %v", err)
+	}
+
+	return order, nil
+}
+
+// WaitForOrder waits for a finalized order to finish processing given only the order url, eg when resuming an order
+// submitted with SubmitFinalizeOrder in another process. The order is fetched repeatedly, honouring any Retry-After
+// header unless IgnoreRetryAfter is set, until the order is valid or invalid.
+// If timeout is 0 the client PollTimeout is used. If the order is still processing when the timeout would be exceeded,
+// the order is returned along with ErrOrderProcessing, and its RetryAfter field indicates when to try again.
+func (c Client) WaitForOrder(account Account, orderURL string, timeout time.Duration) (Order, error) {
+	pollInterval, pollTimeout := c.getPollingDurations()
+	if timeout == 0 {
+		timeout = pollTimeout
+	}
+	end := time.Now().Add(timeout)
+
+	for {
+		order, err := c.fetchOrderRetryAfter(account, orderURL)
+		if err != nil {
+			return order, err
+		}
+
+		if finished, err := checkFinalizedOrderStatus(order); finished {
+			return order, err
+		}
+
+		wait := pollInterval
+		if !c.IgnoreRetryAfter && !order.RetryAfter.IsZero() {
+			if diff := time.Until(order.RetryAfter); diff > wait {
+				wait = diff
+			}
+		}
+		if time.Now().Add(wait).After(end) {
+			if order.RetryAfter.IsZero() {
+				order.RetryAfter = time.Now().Add(wait)
+			}
+			return order, ErrOrderProcessing
+		}
+
+		time.Sleep(wait)
+	}
+}
+
+// Helper function to fetch an order along with the Retry-After header, if present.
+func (c Client) fetchOrderRetryAfter(account Account, orderURL string) (Order, error) {
+	order := Order{
+		URL: orderURL,
+	}
+	resp, err := c.post(orderURL, account.URL, account.PrivateKey, "", &order, http.StatusOK)
+	if err != nil {
+		return order, err
+	}
+
+	order.RetryAfter, err = parseRetryAfter(resp.Header.Get("Retry-After"))
+	if err != nil {
+		return order, fmt.Errorf("acme: error parsing retry-after header: %v", err)
+	}
+
+	return order, nil
 }
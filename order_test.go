@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestClient_NewOrder(t *testing.T) {
@@ -33,6 +34,32 @@ func TestClient_NewOrder(t *testing.T) {
 	}
 }
 
+func TestClient_NewOrderIPs(t *testing.T) {
+	account := makeAccount(t)
+
+	if _, err := testClient.NewOrderIPs(account, "not an ip"); err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	order, err := testClient.NewOrderIPs(account, "10.0.0.1", "2001:DB8::0001")
+	if err != nil {
+		if _, ok := err.(Problem); !ok {
+			t.Fatalf("expected order or Problem, got: %v", err)
+		}
+		t.Skipf("acme server does not support ip identifiers: %v", err)
+	}
+	expected := []Identifier{{IdentifierTypeIP, "10.0.0.1"}, {IdentifierTypeIP, "2001:db8::1"}}
+	for _, id := range expected {
+		found := false
+		for _, orderID := range order.Identifiers {
+			found = found || orderID == id
+		}
+		if !found {
+			t.Fatalf("identifier %+v not found in order identifiers: %+v", id, order.Identifiers)
+		}
+	}
+}
+
 func TestClient_FetchOrder(t *testing.T) {
 	account, order := makeOrder(t)
 
@@ -56,6 +83,56 @@ func TestClient_FinalizeOrder(t *testing.T) {
 	makeOrderFinalised(t, nil)
 }
 
+func TestClient_SubmitFinalizeOrder(t *testing.T) {
+	account, order := makeOrder(t)
+	if err := validateChallenges(t, order, account, nil, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var domains []string
+	for _, id := range order.Identifiers {
+		domains = append(domains, id.Value)
+	}
+	csr, _ := makeCSR(t, domains)
+
+	submittedOrder, err := testClient.SubmitFinalizeOrder(account, order, csr)
+	if err != nil {
+		t.Fatalf("unexpected error submitting finalization: %v", err)
+	}
+	if submittedOrder.Status != "processing" && submittedOrder.Status != "valid" {
+		t.Fatalf("expected processing or valid order, got: %s", submittedOrder.Status)
+	}
+
+	// wait using only the order url, as if from another process
+	finalizedOrder, err := testClient.WaitForOrder(account, order.URL, 0)
+	if err != nil {
+		t.Fatalf("unexpected error waiting for order: %v", err)
+	}
+	if finalizedOrder.Status != "valid" {
+		t.Fatalf("expected valid order, got: %s", finalizedOrder.Status)
+	}
+	if finalizedOrder.Certificate == "" {
+		t.Fatalf("no certificate: %+v", finalizedOrder)
+	}
+	if finalizedOrder.URL != order.URL {
+		t.Fatalf("order url mismatch, expected: %s, got: %s", order.URL, finalizedOrder.URL)
+	}
+}
+
+func TestClient_WaitForOrder(t *testing.T) {
+	account, order := makeOrder(t)
+
+	if _, err := testClient.WaitForOrder(account, testClient.Directory().URL+"/asdasdasd", 0); err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	// order has not been finalized
+	_, err := testClient.WaitForOrder(account, order.URL, 0)
+	if err == nil || !strings.Contains(err.Error(), "not fulfilled") {
+		t.Fatalf("expected authorizations not fulfilled error, got: %v", err)
+	}
+}
+
 func Test_checkFinalizedOrderStatus(t *testing.T) {
 	tests := []struct {
 		Order       Order
@@ -87,6 +164,12 @@ func Test_checkFinalizedOrderStatus(t *testing.T) {
 			HasError:    true,
 			ErrorString: "unexpected",
 		},
+		{
+			Order:       Order{Status: "canceled"},
+			Finished:    true,
+			HasError:    true,
+			ErrorString: "canceled",
+		},
 		{
 			Order:    Order{Status: "processing"},
 			Finished: false,
@@ -193,3 +276,76 @@ func TestClient_NewOrderWithProfile(t *testing.T) {
 		}
 	}
 }
+
+func TestClient_NewOrderExtensionValidity(t *testing.T) {
+	account := makeAccount(t)
+	ids := []Identifier{{"dns", randString() + ".com"}}
+	now := time.Now()
+
+	if _, err := testClient.NewOrderExtension(account, ids, OrderExtension{NotAfter: now.Add(-time.Hour)}); err == nil {
+		t.Fatal("expected error for notAfter in the past, got none")
+	}
+	if _, err := testClient.NewOrderExtension(account, ids, OrderExtension{NotBefore: now.Add(2 * time.Hour), NotAfter: now.Add(time.Hour)}); err == nil {
+		t.Fatal("expected error for notAfter before notBefore, got none")
+	}
+
+	order, err := testClient.NewOrderExtension(account, ids, OrderExtension{NotAfter: now.Add(24 * time.Hour)})
+	if err != nil {
+		if _, ok := err.(Problem); !ok {
+			t.Fatalf("expected order or Problem, got: %v", err)
+		}
+		t.Skipf("acme server does not support requesting validity: %v", err)
+	}
+	if order.Status != "pending" {
+		t.Fatalf("expected pending order, got: %s", order.Status)
+	}
+}
+
+func TestCheckCertificateValidity(t *testing.T) {
+	notBefore := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(24 * time.Hour)
+	cert := &x509.Certificate{NotBefore: notBefore, NotAfter: notAfter.Add(-time.Second)}
+
+	tests := []struct {
+		name     string
+		order    Order
+		cert     *x509.Certificate
+		errorStr string
+	}{
+		{
+			name:     "no certificate",
+			errorStr: "no certificate",
+		},
+		{
+			name: "no validity in order",
+			cert: cert,
+		},
+		{
+			name:  "matching validity",
+			order: Order{NotBefore: notBefore, NotAfter: notAfter},
+			cert:  cert,
+		},
+		{
+			name:     "mismatched notBefore",
+			order:    Order{NotBefore: notBefore.Add(time.Hour)},
+			cert:     cert,
+			errorStr: "notBefore",
+		},
+		{
+			name:     "mismatched notAfter",
+			order:    Order{NotAfter: notAfter.Add(time.Hour)},
+			cert:     cert,
+			errorStr: "notAfter",
+		},
+	}
+
+	for _, ct := range tests {
+		err := CheckCertificateValidity(ct.order, ct.cert)
+		if ct.errorStr == "" && err != nil {
+			t.Errorf("%s: expected no error, got: %v", ct.name, err)
+		}
+		if ct.errorStr != "" && (err == nil || !strings.Contains(err.Error(), ct.errorStr)) {
+			t.Errorf("%s: expected error containing %q, got: %v", ct.name, ct.errorStr, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// OrderListFilter restricts the orders returned by an OrderIterator.
+// Filtering on Status or Identifiers requires each order in the list to be fetched.
+type OrderListFilter struct {
+	// FetchOrders fetches each order as the iterator walks the list, even if no other filter is set.
+	FetchOrders bool
+
+	// Status only matches orders with one of the provided statuses, eg "pending", "ready", "valid".
+	Status []string
+
+	// Identifiers only matches orders containing at least one of the provided identifiers.
+	// Identifier values are compared case-insensitively.
+	Identifiers []Identifier
+}
+
+// Helper function to determine whether orders need to be fetched to apply the filter.
+func (f OrderListFilter) needsFetch() bool {
+	return f.FetchOrders || len(f.Status) > 0 || len(f.Identifiers) > 0
+}
+
+// Helper function to determine whether a fetched order matches the filter.
+func (f OrderListFilter) matches(order Order) bool {
+	if len(f.Status) > 0 {
+		found := false
+		for _, s := range f.Status {
+			if s == order.Status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Identifiers) > 0 {
+		for _, want := range f.Identifiers {
+			for _, have := range order.Identifiers {
+				if want.Type == have.Type && strings.EqualFold(want.Value, have.Value) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// OrderIterator lazily walks every page of an account order list, following the rel="next" Link header.
+// Typical usage:
+//
+//	it := client.IterateOrders(account, acme.OrderListFilter{})
+//	for it.Next() {
+//		fmt.Println(it.OrderURL())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type OrderIterator struct {
+	client  Client
+	account Account
+	filter  OrderListFilter
+
+	next  string
+	seen  map[string]bool
+	urls  []string
+	url   string
+	order Order
+	err   error
+}
+
+// IterateOrders returns an iterator over the orders in the account order list, provided in the account Orders field.
+// No requests are made until the first call to Next.
+func (c Client) IterateOrders(account Account, filter OrderListFilter) *OrderIterator {
+	it := &OrderIterator{
+		client:  c,
+		account: account,
+		filter:  filter,
+		next:    account.Orders,
+		seen:    map[string]bool{},
+	}
+	if account.Orders == "" {
+		it.err = errors.New("no order list for account")
+	}
+	return it
+}
+
+// Next advances the iterator to the next order matching the filter, fetching further pages of the order list as
+// required. Returns false when there are no more orders or an error occurred, see Err.
+func (it *OrderIterator) Next() bool {
+	for it.err == nil {
+		for len(it.urls) == 0 {
+			if it.next == "" {
+				return false
+			}
+			// guard against a server linking back to a page already fetched
+			if it.seen[it.next] {
+				it.err = fmt.Errorf("acme: order list page %q already fetched", it.next)
+				return false
+			}
+			it.seen[it.next] = true
+
+			list, err := it.client.FetchOrderListPage(it.account, it.next)
+			if err != nil {
+				it.err = err
+				return false
+			}
+			it.urls = list.Orders
+			it.next = list.Next
+		}
+
+		it.url, it.urls = it.urls[0], it.urls[1:]
+		it.order = Order{}
+
+		if !it.filter.needsFetch() {
+			return true
+		}
+
+		order, err := it.client.FetchOrder(it.account, it.url)
+		if err != nil {
+			it.err = fmt.Errorf("acme: error fetching order %q: %v", it.url, err)
+			return false
+		}
+		if it.filter.matches(order) {
+			it.order = order
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrderURL returns the url of the current order.
+func (it *OrderIterator) OrderURL() string {
+	return it.url
+}
+
+// Order returns the current order. Only populated if the filter required orders to be fetched.
+func (it *OrderIterator) Order() Order {
+	return it.order
+}
+
+// Err returns the first error encountered while iterating, if any.
+func (it *OrderIterator) Err() error {
+	return it.err
+}
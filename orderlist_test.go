@@ -0,0 +1,129 @@
+package acme
+
+import (
+	"testing"
+)
+
+func TestOrderListFilter_matches(t *testing.T) {
+	order := Order{
+		Status:      "valid",
+		Identifiers: []Identifier{{Type: "dns", Value: "Example.com"}, {Type: "dns", Value: "www.example.com"}},
+	}
+	tests := []struct {
+		name    string
+		filter  OrderListFilter
+		fetch   bool
+		matches bool
+	}{
+		{
+			name:    "empty filter",
+			matches: true,
+		},
+		{
+			name:    "fetch only",
+			filter:  OrderListFilter{FetchOrders: true},
+			fetch:   true,
+			matches: true,
+		},
+		{
+			name:    "matching status",
+			filter:  OrderListFilter{Status: []string{"pending", "valid"}},
+			fetch:   true,
+			matches: true,
+		},
+		{
+			name:   "mismatched status",
+			filter: OrderListFilter{Status: []string{"pending"}},
+			fetch:  true,
+		},
+		{
+			name:    "matching identifier",
+			filter:  OrderListFilter{Identifiers: []Identifier{{Type: "dns", Value: "example.COM"}}},
+			fetch:   true,
+			matches: true,
+		},
+		{
+			name:   "mismatched identifier type",
+			filter: OrderListFilter{Identifiers: []Identifier{{Type: "ip", Value: "example.com"}}},
+			fetch:  true,
+		},
+		{
+			name: "matching identifier mismatched status",
+			filter: OrderListFilter{
+				Status:      []string{"invalid"},
+				Identifiers: []Identifier{{Type: "dns", Value: "example.com"}},
+			},
+			fetch: true,
+		},
+	}
+
+	for _, ct := range tests {
+		if fetch := ct.filter.needsFetch(); fetch != ct.fetch {
+			t.Errorf("%s: expected fetch %t, got %t", ct.name, ct.fetch, fetch)
+		}
+		if matches := ct.filter.matches(order); matches != ct.matches {
+			t.Errorf("%s: expected matches %t, got %t", ct.name, ct.matches, matches)
+		}
+	}
+}
+
+func TestClient_IterateOrders(t *testing.T) {
+	it := testClient.IterateOrders(Account{}, OrderListFilter{})
+	if it.Next() {
+		t.Fatal("expected no orders for account without order list")
+	}
+	if it.Err() == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	if testClientMeta.Software == clientBoulder {
+		t.Skip("boulder doesnt support orders list: https://github.com/letsencrypt/boulder/issues/3335")
+		return
+	}
+
+	account, finalizedOrder, _ := makeOrderFinalised(t, nil)
+	pendingOrder, err := testClient.NewOrderDomains(account, randString()+".com")
+	if err != nil {
+		t.Fatalf("unexpected error making order: %v", err)
+	}
+	account, err = testClient.UpdateAccount(account)
+	if err != nil {
+		t.Fatalf("unexpected error updating account: %v", err)
+	}
+
+	var urls []string
+	it = testClient.IterateOrders(account, OrderListFilter{})
+	for it.Next() {
+		urls = append(urls, it.OrderURL())
+		if it.Order().URL != "" {
+			t.Fatalf("expected unfetched order, got: %+v", it.Order())
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error iterating orders: %v", err)
+	}
+	if len(urls) != 2 {
+		t.Fatalf("expected 2 orders, got: %d - %v", len(urls), urls)
+	}
+
+	it = testClient.IterateOrders(account, OrderListFilter{Status: []string{"valid"}})
+	var orders []Order
+	for it.Next() {
+		orders = append(orders, it.Order())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error iterating orders: %v", err)
+	}
+	if len(orders) != 1 || orders[0].URL != finalizedOrder.URL {
+		t.Fatalf("expected finalized order %s, got: %+v", finalizedOrder.URL, orders)
+	}
+
+	it = testClient.IterateOrders(account, OrderListFilter{Identifiers: pendingOrder.Identifiers})
+	orders = nil
+	for it.Next() {
+		orders = append(orders, it.Order())
+	}
+	if len(orders) != 1 || orders[0].URL != pendingOrder.URL {
+		t.Fatalf("expected pending order %s, got: %+v", pendingOrder.URL, orders)
+	}
+}
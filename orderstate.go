@@ -0,0 +1,74 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OrderState is a serializable record of an in-flight order, provided to CertificateRequest.SaveState as an order
+// progresses. It allows an order to be continued with ResumeCertificate, eg after a process restart, instead of
+// creating a new order.
+type OrderState struct {
+	// DirectoryURL of the acme server the order was created with.
+	DirectoryURL string `json:"directoryUrl"`
+
+	// AccountURL of the account which created the order.
+	AccountURL string `json:"accountUrl"`
+
+	// OrderURL of the order.
+	OrderURL string `json:"orderUrl"`
+
+	// Identifiers in the order.
+	Identifiers []Identifier `json:"identifiers"`
+
+	// Challenges chosen for each authorization, keyed by authorization url.
+	Challenges map[string]OrderStateChallenge `json:"challenges,omitempty"`
+
+	// KeyRef is the reference to the certificate private key provided in CertificateRequest.KeyRef.
+	KeyRef string `json:"keyRef,omitempty"`
+}
+
+// OrderStateChallenge is the challenge chosen to fulfil an authorization.
+type OrderStateChallenge struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// ResumeCertificate continues an order previously started by ObtainCertificate given its saved state. The order is
+// re-fetched and continued from whichever step was left incomplete: fulfilling authorizations, finalizing, waiting
+// for the order to be processed, or fetching the certificate chain.
+//
+// The request provides the solvers, preferred chain and SaveState hook. Identifiers, Profile, validity and Replaces
+// are ignored as the order already exists. The request PrivateKey must be the key referred to by the state KeyRef
+// if the order has been finalized, otherwise a new key is generated if not provided.
+func (c Client) ResumeCertificate(account Account, state OrderState, req CertificateRequest) (CertificateResult, error) {
+	result := CertificateResult{}
+
+	if state.OrderURL == "" {
+		return result, errors.New("acme: no order url in order state")
+	}
+	if state.DirectoryURL != "" && state.DirectoryURL != c.dir.URL {
+		return result, fmt.Errorf("acme: order state directory %q does not match client directory %q", state.DirectoryURL, c.dir.URL)
+	}
+	if state.AccountURL != "" && state.AccountURL != account.URL {
+		return result, fmt.Errorf("acme: order state account %q does not match account %q", state.AccountURL, account.URL)
+	}
+
+	order, err := c.FetchOrder(account, state.OrderURL)
+	if err != nil {
+		return result, fmt.Errorf("acme: error fetching order %q: %v", state.OrderURL, err)
+	}
+
+	result.PrivateKey = req.PrivateKey
+	if result.PrivateKey == nil {
+		if order.Status == "processing" || order.Status == "valid" {
+			return result, fmt.Errorf("acme: private key required to resume %s order", order.Status)
+		}
+		result.PrivateKey, err = generatePrivateKey(req.KeyType)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return c.completeOrder(account, order, state, req, result)
+}
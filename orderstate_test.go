@@ -0,0 +1,110 @@
+package acme
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestClient_ResumeCertificate(t *testing.T) {
+	account := makeAccount(t)
+
+	var states []OrderState
+	saveState := func(state OrderState) error {
+		states = append(states, state)
+		return nil
+	}
+
+	// simulate a failure partway through an order
+	_, err := testClient.ObtainCertificate(account, CertificateRequest{
+		Identifiers: []Identifier{{Type: "dns", Value: randString() + ".com"}},
+		Solvers:     map[string]Solver{ChallengeTypeHTTP01: &testSolver{presentErr: errors.New("crashed")}},
+		KeyRef:      "test-key",
+		SaveState:   saveState,
+	})
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+	if len(states) != 2 {
+		t.Fatalf("expected 2 saved states, got: %d", len(states))
+	}
+	state := states[len(states)-1]
+	if state.OrderURL == "" || state.KeyRef != "test-key" || state.AccountURL != account.URL {
+		t.Fatalf("unexpected order state: %+v", state)
+	}
+	if len(state.Challenges) != 1 {
+		t.Fatalf("expected 1 chosen challenge, got: %+v", state.Challenges)
+	}
+
+	// round trip the state as if it were persisted
+	b, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling state: %v", err)
+	}
+	var loadedState OrderState
+	if err := json.Unmarshal(b, &loadedState); err != nil {
+		t.Fatalf("unexpected error unmarshalling state: %v", err)
+	}
+	if !reflect.DeepEqual(state, loadedState) {
+		t.Fatalf("state mismatch, expected: %+v, got: %+v", state, loadedState)
+	}
+
+	if _, err := testClient.ResumeCertificate(makeAccount(t), loadedState, CertificateRequest{}); err == nil {
+		t.Fatal("expected error resuming with a different account, got none")
+	}
+
+	solver := &testSolver{}
+	result, err := testClient.ResumeCertificate(account, loadedState, CertificateRequest{
+		Solvers: map[string]Solver{
+			ChallengeTypeDNS01:  solver,
+			ChallengeTypeHTTP01: solver,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error resuming order: %v", err)
+	}
+	if result.Order.URL != loadedState.OrderURL {
+		t.Fatalf("expected resumed order %s, got: %s", loadedState.OrderURL, result.Order.URL)
+	}
+	if result.Order.Status != "valid" || len(result.Certificates) == 0 {
+		t.Fatalf("expected valid order with certificates, got: %+v", result.Order)
+	}
+
+	// resuming a valid order only fetches the certificates, and requires the certificate key
+	if _, err := testClient.ResumeCertificate(account, loadedState, CertificateRequest{}); err == nil {
+		t.Fatal("expected error resuming valid order without key, got none")
+	}
+	result, err = testClient.ResumeCertificate(account, loadedState, CertificateRequest{PrivateKey: result.PrivateKey})
+	if err != nil {
+		t.Fatalf("unexpected error resuming valid order: %v", err)
+	}
+	if len(result.Certificates) == 0 {
+		t.Fatal("no certs returned")
+	}
+}
+
+func TestClient_ResumeCertificate2(t *testing.T) {
+	account := makeAccount(t)
+	tests := []struct {
+		name  string
+		state OrderState
+	}{
+		{
+			name: "no order url",
+		},
+		{
+			name:  "mismatched directory",
+			state: OrderState{OrderURL: "https://x/order", DirectoryURL: "https://x/directory"},
+		},
+		{
+			name:  "mismatched account",
+			state: OrderState{OrderURL: "https://x/order", AccountURL: "https://x/account"},
+		},
+	}
+	for _, ct := range tests {
+		if _, err := testClient.ResumeCertificate(account, ct.state, CertificateRequest{}); err == nil {
+			t.Errorf("%s: expected error, got none", ct.name)
+		}
+	}
+}
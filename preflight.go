@@ -0,0 +1,237 @@
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	preflightDefaultTimeout      = 10 * time.Second
+	preflightDefaultMaxRedirects = 10
+
+	// maximum number of response body bytes read, and included in diagnostics
+	preflightMaxBody     = 1024
+	preflightMaxBodyDiag = 128
+)
+
+// HTTP01Preflight checks a http-01 challenge is being served correctly before the acme server is asked to validate
+// it, the way the acme server would: requesting the challenge url on port 80, following redirects to http or https
+// urls on the default ports, without verifying https certificates, and comparing the body to the key authorization.
+// See https://tools.ietf.org/html/rfc8555#section-8.3
+type HTTP01Preflight struct {
+	// Resolver used to resolve host names.
+	// Default net.DefaultResolver if not set.
+	Resolver *net.Resolver
+
+	// Dial connects to a resolved address, eg to route the request through a specific network.
+	// Default a net.Dialer if not set.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Timeout for the entire request, including redirects.
+	// Default 10 seconds if not set.
+	Timeout time.Duration
+
+	// MaxRedirects followed.
+	// Default 10 if not set.
+	MaxRedirects int
+}
+
+// HTTP01PreflightError describes a failed http-01 preflight check.
+type HTTP01PreflightError struct {
+	// URL initially requested.
+	URL string
+
+	// Redirects followed, in order.
+	Redirects []string
+
+	// StatusCode and Body, possibly truncated, of the final response if one was received.
+	StatusCode int
+	Body       string
+
+	// Expected key authorization.
+	Expected string
+
+	// Err is the error making the request, if any.
+	Err error
+}
+
+// Error describes the redirect chain and what was received.
+func (e HTTP01PreflightError) Error() string {
+	chain := strings.Join(append([]string{e.URL}, e.Redirects...), " -> ")
+	if e.Err != nil {
+		return fmt.Sprintf("acme: http-01 preflight request %s failed: %v", chain, e.Err)
+	}
+	body := e.Body
+	if len(body) > preflightMaxBodyDiag {
+		body = body[:preflightMaxBodyDiag] + "..."
+	}
+	if e.StatusCode != http.StatusOK {
+		return fmt.Sprintf("acme: http-01 preflight request %s returned status %d with body %q", chain, e.StatusCode, body)
+	}
+	return fmt.Sprintf("acme: http-01 preflight request %s returned body %q, expected %q", chain, body, e.Expected)
+}
+
+// Check requests the http-01 challenge url for the identifier and checks the response is the challenge key
+// authorization. Returns a HTTP01PreflightError if the check fails.
+func (p HTTP01Preflight) Check(identifier Identifier, chal Challenge) error {
+	if err := checkHTTP01Token(chal.Token); err != nil {
+		return err
+	}
+	u, err := HTTP01ChallengeURL(identifier, chal.Token)
+	if err != nil {
+		return err
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = preflightDefaultTimeout
+	}
+	maxRedirects := p.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = preflightDefaultMaxRedirects
+	}
+
+	perr := HTTP01PreflightError{URL: u, Expected: chal.KeyAuthorization}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:       p.dialContext,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			perr.Redirects = append(perr.Redirects, req.URL.String())
+			if len(via) > maxRedirects {
+				return fmt.Errorf("too many redirects: %d", len(via))
+			}
+			return checkPreflightRedirect(req)
+		},
+	}
+
+	resp, err := client.Get(u)
+	if err != nil {
+		perr.Err = err
+		return perr
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, preflightMaxBody))
+	if err != nil {
+		perr.Err = err
+		return perr
+	}
+	perr.StatusCode = resp.StatusCode
+	perr.Body = string(body)
+
+	// trailing whitespace is ignored by the acme server
+	if resp.StatusCode != http.StatusOK || strings.TrimRight(perr.Body, " \t\r\n") != chal.KeyAuthorization {
+		return perr
+	}
+
+	return nil
+}
+
+// Helper function to check a redirect is one the acme server would follow, to a http or https url on the default
+// port for the scheme.
+func checkPreflightRedirect(req *http.Request) error {
+	port := req.URL.Port()
+	switch req.URL.Scheme {
+	case "http":
+		if port != "" && port != "80" {
+			return fmt.Errorf("redirect to unsupported port: %s", port)
+		}
+	case "https":
+		if port != "" && port != "443" {
+			return fmt.Errorf("redirect to unsupported port: %s", port)
+		}
+	default:
+		return fmt.Errorf("redirect to unsupported scheme: %q", req.URL.Scheme)
+	}
+	return nil
+}
+
+// Helper function to resolve the host of an address with the configured resolver and connect to the first
+// reachable address with the configured dialer.
+func (p HTTP01Preflight) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		resolver := p.Resolver
+		if resolver == nil {
+			resolver = net.DefaultResolver
+		}
+		addrs, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving %s: %v", host, err)
+		}
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
+	}
+
+	dial := p.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	var errs []string
+	for _, ip := range ips {
+		conn, err := dial(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, errors.New(strings.Join(errs, "; "))
+}
+
+// HTTP01PreflightSolver wraps a http-01 Solver, checking the challenge is served correctly with a preflight check
+// once presented, before the challenge is updated. This avoids failed validations, which may count against acme
+// server rate limits, failing fast with a HTTP01PreflightError instead.
+type HTTP01PreflightSolver struct {
+	Solver    Solver
+	Preflight HTTP01Preflight
+}
+
+// Present presents the challenge with the wrapped solver.
+func (s HTTP01PreflightSolver) Present(account Account, auth Authorization, chal Challenge) error {
+	if s.Solver == nil {
+		return errors.New("acme: no solver to preflight")
+	}
+	return s.Solver.Present(account, auth, chal)
+}
+
+// CleanUp cleans up the challenge with the wrapped solver.
+func (s HTTP01PreflightSolver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	if s.Solver == nil {
+		return nil
+	}
+	return s.Solver.CleanUp(account, auth, chal)
+}
+
+// Wait waits with the wrapped solver if it implements SolverWaiter, then runs the preflight check.
+func (s HTTP01PreflightSolver) Wait(account Account, auth Authorization, chal Challenge) error {
+	if waiter, ok := s.Solver.(SolverWaiter); ok {
+		if err := waiter.Wait(account, auth, chal); err != nil {
+			return err
+		}
+	}
+	return s.Preflight.Check(auth.Identifier, chal)
+}
@@ -0,0 +1,146 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTP01Preflight_Check(t *testing.T) {
+	chal := Challenge{Type: ChallengeTypeHTTP01, Token: "token", KeyAuthorization: "token.thumbprint"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(HTTP01ChallengePath+"token", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host {
+		case "example.com":
+			w.Write([]byte(chal.KeyAuthorization + "\n"))
+		case "wrong.example.com":
+			w.Write([]byte("not the key authorization"))
+		case "redirect.example.com":
+			http.Redirect(w, r, "https://example.com/elsewhere", http.StatusFound)
+		case "badport.example.com":
+			http.Redirect(w, r, "http://example.com:8080/", http.StatusFound)
+		case "loop.example.com":
+			http.Redirect(w, r, r.URL.String(), http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	mux.HandleFunc("/elsewhere", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(chal.KeyAuthorization))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	tlsSrv := httptest.NewTLSServer(mux)
+	defer tlsSrv.Close()
+
+	dnsSrv := newTestDNSServer(t, "example.com.", nil)
+	defer dnsSrv.close()
+	for _, name := range []string{"example.com.", "wrong.example.com.", "redirect.example.com.", "badport.example.com.", "loop.example.com.", "missing.example.com."} {
+		dnsSrv.add(dnsRR{name: name, typ: dnsTypeA, class: dnsClassINET, data: net.IPv4(127, 0, 0, 1).To4()})
+	}
+
+	var dialed []string
+	p := HTTP01Preflight{
+		Resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, dnsSrv.addr)
+			},
+		},
+		// route port 80 and 443 to the test servers
+		Dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			var d net.Dialer
+			if strings.HasSuffix(addr, ":443") {
+				return d.DialContext(ctx, network, tlsSrv.Listener.Addr().String())
+			}
+			return d.DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}
+
+	if err := p.Check(Identifier{Type: "dns", Value: "example.com"}, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dialed) != 1 || dialed[0] != "127.0.0.1:80" {
+		t.Fatalf("unexpected dialed addresses: %v", dialed)
+	}
+
+	if err := p.Check(Identifier{Type: "dns", Value: "redirect.example.com"}, chal); err != nil {
+		t.Fatalf("unexpected error following redirect: %v", err)
+	}
+
+	tests := []struct {
+		domain   string
+		contains string
+	}{
+		{domain: "wrong.example.com", contains: `returned body "not the key authorization", expected "token.thumbprint"`},
+		{domain: "missing.example.com", contains: "returned status 404"},
+		{domain: "badport.example.com", contains: "-> http://example.com:8080/ failed: "},
+		{domain: "loop.example.com", contains: "too many redirects"},
+		{domain: "unknown.example.com", contains: "error resolving"},
+	}
+	for _, ct := range tests {
+		err := p.Check(Identifier{Type: "dns", Value: ct.domain}, chal)
+		if err == nil {
+			t.Errorf("%s: expected error, got none", ct.domain)
+			continue
+		}
+		if _, ok := err.(HTTP01PreflightError); !ok {
+			t.Errorf("%s: expected preflight error, got: %T", ct.domain, err)
+		}
+		if !strings.Contains(err.Error(), ct.contains) {
+			t.Errorf("%s: expected error containing %q, got: %v", ct.domain, ct.contains, err)
+		}
+	}
+
+	if err := p.Check(Identifier{Type: "dns", Value: "example.com"}, Challenge{Token: "../x"}); err == nil {
+		t.Fatal("expected invalid token error, got none")
+	}
+}
+
+func TestHTTP01PreflightSolver(t *testing.T) {
+	store := &MemoryTokenStore{}
+	srv := httptest.NewServer(HTTP01Handler(store, nil))
+	defer srv.Close()
+
+	solver := HTTP01PreflightSolver{
+		Solver: HTTP01StoreSolver{Store: store},
+		Preflight: HTTP01Preflight{
+			Dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, srv.Listener.Addr().String())
+			},
+		},
+	}
+	var _ SolverWaiter = solver
+
+	auth := Authorization{Identifier: Identifier{Type: "ip", Value: "192.0.2.1"}}
+	chal := Challenge{Type: ChallengeTypeHTTP01, Token: "token", KeyAuthorization: "token.thumbprint"}
+
+	if err := solver.Wait(Account{}, auth, chal); err == nil {
+		t.Fatal("expected preflight error before present, got none")
+	}
+	if err := solver.Present(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := solver.Wait(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected preflight error: %v", err)
+	}
+	if err := solver.CleanUp(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waiter := &testWaitSolver{waitErr: errors.New("WAIT ERROR")}
+	if err := (HTTP01PreflightSolver{Solver: waiter}).Wait(Account{}, auth, chal); err == nil || !strings.Contains(err.Error(), "WAIT") {
+		t.Fatalf("expected wrapped wait error, got: %v", err)
+	}
+	if err := (HTTP01PreflightSolver{}).Present(Account{}, auth, chal); err == nil {
+		t.Fatal("expected no solver error, got none")
+	}
+}
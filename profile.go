@@ -0,0 +1,75 @@
+package acme
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Profile describes a certificate profile advertised by an acme server.
+// See https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+type Profile struct {
+	// Name of the profile, as used in OrderExtension.Profile
+	Name string
+
+	// Description of the profile, as provided by the acme server. Typically a human readable string or url.
+	Description string
+
+	// Lifetime is the expected lifetime of certificates issued under this profile, if provided to the client with
+	// WithProfileLifetime, otherwise 0.
+	Lifetime time.Duration
+}
+
+// Profiles returns the certificate profiles advertised by the acme server directory, sorted by name.
+func (c Client) Profiles() []Profile {
+	var profiles []Profile
+	for name := range c.dir.Meta.Profiles {
+		profile, _ := c.Profile(name)
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
+// Profile returns a single certificate profile advertised by the acme server directory, and whether it exists.
+func (c Client) Profile(name string) (Profile, bool) {
+	description, ok := c.dir.Meta.Profiles[name]
+	if !ok {
+		return Profile{}, false
+	}
+	return Profile{
+		Name:        name,
+		Description: description,
+		Lifetime:    c.profileLifetimes[name],
+	}, true
+}
+
+// DefaultProfile returns the profile requested in new orders which don't otherwise specify a profile, if set using
+// WithDefaultProfile.
+func (c Client) DefaultProfile() string {
+	return c.defaultProfile
+}
+
+// Allowed difference between a certificate lifetime and the expected profile lifetime, allowing for CAs backdating
+// notBefore or treating notAfter as inclusive.
+const profileLifetimeTolerance = time.Hour
+
+// CheckCertificateProfile checks the lifetime of an issued certificate matches the expected lifetime of the profile.
+// Returns nil if the profile has no expected lifetime.
+func CheckCertificateProfile(cert *x509.Certificate, profile Profile) error {
+	if cert == nil {
+		return errors.New("acme: no certificate provided")
+	}
+	if profile.Lifetime == 0 {
+		return nil
+	}
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	if absDuration(lifetime-profile.Lifetime) > profileLifetimeTolerance {
+		return fmt.Errorf("acme: certificate lifetime %v does not match profile %q lifetime %v", lifetime, profile.Name, profile.Lifetime)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package acme
+
+import (
+	"crypto/x509"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Profiles(t *testing.T) {
+	c := Client{}
+	if profiles := c.Profiles(); len(profiles) != 0 {
+		t.Fatalf("expected no profiles, got: %+v", profiles)
+	}
+
+	c.dir.Meta.Profiles = map[string]string{
+		"tlsserver":  "https://example.com/tlsserver",
+		"classic":    "The same profile you're accustomed to",
+		"shortlived": "https://example.com/shortlived",
+	}
+	if err := WithProfileLifetime("shortlived", 160*time.Hour)(&c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Profile{
+		{Name: "classic", Description: "The same profile you're accustomed to"},
+		{Name: "shortlived", Description: "https://example.com/shortlived", Lifetime: 160 * time.Hour},
+		{Name: "tlsserver", Description: "https://example.com/tlsserver"},
+	}
+	if profiles := c.Profiles(); !reflect.DeepEqual(profiles, expected) {
+		t.Fatalf("profiles mismatch, expected: %+v, got: %+v", expected, profiles)
+	}
+
+	if _, ok := c.Profile("missing"); ok {
+		t.Fatal("expected missing profile")
+	}
+}
+
+func TestCheckCertificateProfile(t *testing.T) {
+	notBefore := time.Now().Add(-time.Hour)
+	cert := &x509.Certificate{NotBefore: notBefore, NotAfter: notBefore.Add(160*time.Hour - time.Second)}
+
+	tests := []struct {
+		name     string
+		cert     *x509.Certificate
+		profile  Profile
+		errorStr string
+	}{
+		{
+			name:     "no certificate",
+			errorStr: "no certificate",
+		},
+		{
+			name:    "no lifetime",
+			cert:    cert,
+			profile: Profile{Name: "classic"},
+		},
+		{
+			name:    "matching lifetime",
+			cert:    cert,
+			profile: Profile{Name: "shortlived", Lifetime: 160 * time.Hour},
+		},
+		{
+			name:     "mismatched lifetime",
+			cert:     cert,
+			profile:  Profile{Name: "classic", Lifetime: 90 * 24 * time.Hour},
+			errorStr: "does not match profile",
+		},
+	}
+
+	for _, ct := range tests {
+		err := CheckCertificateProfile(ct.cert, ct.profile)
+		if ct.errorStr == "" && err != nil {
+			t.Errorf("%s: expected no error, got: %v", ct.name, err)
+		}
+		if ct.errorStr != "" && (err == nil || !strings.Contains(err.Error(), ct.errorStr)) {
+			t.Errorf("%s: expected error containing %q, got: %v", ct.name, ct.errorStr, err)
+		}
+	}
+}
+
+func TestClient_DefaultProfile(t *testing.T) {
+	opts := append([]OptionFunc{WithDefaultProfile(randString())}, testClientMeta.Options...)
+	if _, err := NewClient(testClient.Directory().URL, opts...); err == nil {
+		t.Fatal("expected error for unadvertised default profile, got none")
+	}
+
+	profiles := testClient.Profiles()
+	if len(profiles) == 0 {
+		t.Skip("acme server does not support profiles")
+		return
+	}
+
+	tc2 := testClient
+	tc2.defaultProfile = profiles[0].Name
+
+	account := makeAccount(t)
+	order, err := tc2.NewOrderDomains(account, randString()+".com")
+	if err != nil {
+		t.Fatalf("unexpected error making order: %v", err)
+	}
+	if order.Profile != profiles[0].Name {
+		t.Fatalf("order profile mismatch, expected: %q, got: %q", profiles[0].Name, order.Profile)
+	}
+}
+
+func TestClient_ReplacementOrderFromOrder(t *testing.T) {
+	if testClient.dir.RenewalInfo == "" {
+		t.Skip("acme server does not support ari renewals")
+		return
+	}
+	profiles := testClient.Profiles()
+	if len(profiles) == 0 {
+		t.Skip("acme server does not support profiles")
+		return
+	}
+
+	account := makeAccount(t)
+	result, err := testClient.ObtainCertificate(account, CertificateRequest{
+		Identifiers: []Identifier{{Type: "dns", Value: randString() + ".com"}},
+		Profile:     profiles[0].Name,
+		Solvers:     map[string]Solver{ChallengeTypeHTTP01: &testSolver{}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error obtaining certificate: %v", err)
+	}
+	if result.Order.Profile != profiles[0].Name {
+		t.Fatalf("order profile mismatch, expected: %q, got: %q", profiles[0].Name, result.Order.Profile)
+	}
+
+	newOrder, err := testClient.ReplacementOrderFromOrder(account, result.Order, result.Certificates[0])
+	if err != nil {
+		t.Fatalf("unexpected error replacing order: %v", err)
+	}
+	if newOrder.Profile != result.Order.Profile {
+		t.Fatalf("replacement order profile mismatch, expected: %q, got: %q", result.Order.Profile, newOrder.Profile)
+	}
+	if !reflect.DeepEqual(newOrder.Identifiers, result.Order.Identifiers) {
+		t.Fatalf("replacement order identifiers mismatch")
+	}
+}
@@ -0,0 +1,241 @@
+package acme
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	propagationDefaultTimeout      = 2 * time.Minute
+	propagationDefaultInterval     = 2 * time.Second
+	propagationDefaultQueryTimeout = 5 * time.Second
+
+	// maximum number of cnames followed from a record name
+	propagationMaxCNAMEs = 8
+)
+
+// DNSPropagationChecker waits for a TXT record to be published by every authoritative nameserver of its zone, eg so
+// a dns-01 challenge isn't updated before the acme server is able to see the record.
+//
+// The authoritative nameservers are found using the Resolver, and are then queried directly. If the record name is
+// a CNAME, the target is checked at the authoritative nameservers of its own zone.
+type DNSPropagationChecker struct {
+	// Resolver used to look up the authoritative nameservers of a zone, and their addresses.
+	// Default net.DefaultResolver if not set.
+	Resolver *net.Resolver
+
+	// Port the authoritative nameservers are queried on.
+	// Default 53 if not set.
+	Port int
+
+	// Timeout after which Wait gives up.
+	// Default 2 minutes if not set.
+	Timeout time.Duration
+
+	// Interval between checks in Wait.
+	// Default 2 seconds if not set.
+	Interval time.Duration
+
+	// QueryTimeout for each lookup or query.
+	// Default 5 seconds if not set.
+	QueryTimeout time.Duration
+}
+
+// Wait checks the TXT record value at the fully qualified domain name until it has propagated to every authoritative
+// nameserver, or the timeout is reached.
+func (c DNSPropagationChecker) Wait(fqdn, value string) error {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = propagationDefaultTimeout
+	}
+	interval := c.Interval
+	if interval <= 0 {
+		interval = propagationDefaultInterval
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := c.Check(fqdn, value)
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("acme: timeout after %s waiting for %s to propagate: %v", timeout, fqdn, err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Check queries every authoritative nameserver once, returning an error if any of them don't have a TXT record with
+// the value at the fully qualified domain name.
+func (c DNSPropagationChecker) Check(fqdn, value string) error {
+	name := fqdn
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+
+	for i := 0; i <= propagationMaxCNAMEs; i++ {
+		nameservers, err := c.authoritativeNameservers(name)
+		if err != nil {
+			return err
+		}
+
+		target := ""
+		for _, ns := range nameservers {
+			values, cname, err := c.queryTXT(ns, name)
+			if err != nil {
+				return err
+			}
+			if cname != "" {
+				target = cname
+				break
+			}
+			if !containsValue(values, value) {
+				return fmt.Errorf("acme: txt record %s not found at nameserver %s", name, ns)
+			}
+		}
+		if target == "" {
+			return nil
+		}
+		name = target
+	}
+
+	return fmt.Errorf("acme: too many cnames following %s", fqdn)
+}
+
+func (c DNSPropagationChecker) resolver() *net.Resolver {
+	if c.Resolver != nil {
+		return c.Resolver
+	}
+	return net.DefaultResolver
+}
+
+func (c DNSPropagationChecker) queryTimeout() time.Duration {
+	if c.QueryTimeout > 0 {
+		return c.QueryTimeout
+	}
+	return propagationDefaultQueryTimeout
+}
+
+// Helper function to find the addresses of the authoritative nameservers for a name, looking up the nameservers of
+// each parent domain in turn until the zone is found.
+func (c DNSPropagationChecker) authoritativeNameservers(name string) ([]string, error) {
+	port := "53"
+	if c.Port > 0 {
+		port = strconv.Itoa(c.Port)
+	}
+
+	for zone := name; strings.Contains(strings.TrimSuffix(zone, "."), "."); zone = zone[strings.Index(zone, ".")+1:] {
+		ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout())
+		nss, err := c.resolver().LookupNS(ctx, zone)
+		cancel()
+		if err != nil {
+			// only continue to the parent domain if the name has no nameservers, otherwise the parent or tld
+			// nameservers would be queried instead
+			if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+				continue
+			}
+			return nil, fmt.Errorf("acme: error looking up nameservers for %s: %v", zone, err)
+		}
+		if len(nss) == 0 {
+			continue
+		}
+
+		var addrs []string
+		for _, ns := range nss {
+			ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout())
+			hosts, err := c.resolver().LookupHost(ctx, ns.Host)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("acme: error looking up nameserver %s for %s: %v", ns.Host, zone, err)
+			}
+			for _, h := range hosts {
+				addrs = append(addrs, net.JoinHostPort(h, port))
+			}
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("acme: no nameserver addresses for %s", zone)
+		}
+		return addrs, nil
+	}
+
+	return nil, fmt.Errorf("acme: no authoritative nameservers found for %s", name)
+}
+
+// Helper function to query a nameserver directly for the TXT record values at a name. If the name is a CNAME which
+// the nameserver doesn't resolve to a TXT record, the target of the CNAME is returned instead.
+func (c DNSPropagationChecker) queryTXT(nameserver, name string) ([]string, string, error) {
+	query, err := dnsMsg{
+		id:        dnsMsgID(),
+		flags:     dnsOpcodeFlags(dnsOpcodeQuery),
+		questions: []dnsQuestion{{name: name, typ: dnsTypeTXT, class: dnsClassINET}},
+	}.pack()
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := dnsExchange(nameserver, query, c.queryTimeout())
+	if err != nil {
+		return nil, "", err
+	}
+	m, err := parseDNSMsg(resp)
+	if err != nil {
+		return nil, "", err
+	}
+	switch rcode := m.rcode(); rcode {
+	case dnsRcodeSuccess:
+	case dnsRcodeNXDomain:
+		return nil, "", nil
+	default:
+		return nil, "", fmt.Errorf("acme: error querying %s at nameserver %s: %s", name, nameserver, dnsRcodeName(rcode))
+	}
+
+	// follow any cname chain included in the answer
+	current := name
+	for i := 0; i < propagationMaxCNAMEs; i++ {
+		next := ""
+		for _, rr := range m.answers {
+			if rr.typ == dnsTypeCNAME && dnsNameEqual(rr.name, current) {
+				next, _, err = readDNSName(resp, rr.dataOff)
+				if err != nil {
+					return nil, "", err
+				}
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		current = next
+	}
+
+	var values []string
+	for _, rr := range m.answers {
+		if rr.typ != dnsTypeTXT || !dnsNameEqual(rr.name, current) {
+			continue
+		}
+		v, err := parseDNSTXTData(rr.data)
+		if err != nil {
+			return nil, "", err
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 && current != name {
+		return nil, current, nil
+	}
+
+	return values, "", nil
+}
+
+// Helper function to check whether a value is in a list of values.
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package acme
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Helper function to create a propagation checker using a test nameserver for both resolving and querying.
+func newTestPropagationChecker(t *testing.T, srv *testDNSServer) DNSPropagationChecker {
+	ns, _ := packDNSName("ns.example.com.")
+	srv.add(dnsRR{name: "example.com.", typ: dnsTypeNS, class: dnsClassINET, data: ns})
+	srv.add(dnsRR{name: "ns.example.com.", typ: dnsTypeA, class: dnsClassINET, data: net.IPv4(127, 0, 0, 1).To4()})
+
+	_, port, _ := net.SplitHostPort(srv.addr)
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("bad port: %v", err)
+	}
+
+	return DNSPropagationChecker{
+		Resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, srv.addr)
+			},
+		},
+		Port:         p,
+		Timeout:      time.Second,
+		Interval:     50 * time.Millisecond,
+		QueryTimeout: time.Second,
+	}
+}
+
+func TestDNSPropagationChecker_Check(t *testing.T) {
+	srv := newTestDNSServer(t, "example.com.", nil)
+	defer srv.close()
+	c := newTestPropagationChecker(t, srv)
+
+	fqdn := "_acme-challenge.www.example.com"
+	if err := c.Check(fqdn, "value"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %v", err)
+	}
+
+	srv.add(dnsRR{name: fqdn + ".", typ: dnsTypeTXT, class: dnsClassINET, data: dnsTXTData("other")})
+	if err := c.Check(fqdn, "value"); err == nil {
+		t.Fatal("expected error with mismatched value, got none")
+	}
+
+	srv.add(dnsRR{name: fqdn + ".", typ: dnsTypeTXT, class: dnsClassINET, data: dnsTXTData("value")})
+	if err := c.Check(fqdn, "value"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// cname to a validation domain
+	target, _ := packDNSName("_acme-challenge.validation.example.com.")
+	srv.add(dnsRR{name: "_acme-challenge.alias.example.com.", typ: dnsTypeCNAME, class: dnsClassINET, data: target})
+	if err := c.Check("_acme-challenge.alias.example.com.", "aliased"); err == nil {
+		t.Fatal("expected error before cname target published, got none")
+	}
+	srv.add(dnsRR{name: "_acme-challenge.validation.example.com.", typ: dnsTypeTXT, class: dnsClassINET, data: dnsTXTData("aliased")})
+	if err := c.Check("_acme-challenge.alias.example.com.", "aliased"); err != nil {
+		t.Fatalf("unexpected error following cname: %v", err)
+	}
+
+	// lookup errors other than not found are returned, rather than moving on to the parent domain
+	if err := c.Check("_acme-challenge.example.org", "value"); err == nil || !strings.Contains(err.Error(), "error looking up nameservers for _acme-challenge.example.org.") {
+		t.Fatalf("expected lookup error, got: %v", err)
+	}
+}
+
+func TestDNSPropagationChecker_Wait(t *testing.T) {
+	srv := newTestDNSServer(t, "example.com.", nil)
+	defer srv.close()
+	c := newTestPropagationChecker(t, srv)
+
+	fqdn := "_acme-challenge.example.com."
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		srv.add(dnsRR{name: fqdn, typ: dnsTypeTXT, class: dnsClassINET, data: dnsTXTData("value")})
+	}()
+	if err := c.Wait(fqdn, "value"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.Timeout = 200 * time.Millisecond
+	if err := c.Wait(fqdn, "missing"); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected timeout error, got: %v", err)
+	}
+
+	// solvers opt in to waiting for propagation
+	chal := Challenge{Type: ChallengeTypeDNS01, KeyAuthorization: "token.thumbprint"}
+	auth := Authorization{Identifier: Identifier{Type: "dns", Value: "example.com"}}
+	var _ SolverWaiter = DNS01Solver{}
+	if err := (DNS01Solver{}).Wait(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error without propagation checker: %v", err)
+	}
+	solver := DNS01Solver{Propagation: &c}
+	if err := solver.Wait(Account{}, auth, chal); err == nil {
+		t.Fatal("expected timeout error, got none")
+	}
+	srv.add(dnsRR{name: fqdn, typ: dnsTypeTXT, class: dnsClassINET, data: dnsTXTData(EncodeDNS01KeyAuthorization(chal.KeyAuthorization))})
+	if err := solver.Wait(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
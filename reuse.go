@@ -0,0 +1,256 @@
+package acme
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OrderIndex is a local index of order urls keyed by identifier set, used to find existing orders when the acme
+// server doesn't provide an account order list, or to avoid walking it.
+type OrderIndex interface {
+	// LookupOrders returns the urls of orders previously added for an account and identifier set.
+	LookupOrders(accountURL string, identifiers []Identifier) ([]string, error)
+
+	// AddOrder records an order url for an account and identifier set.
+	AddOrder(accountURL string, identifiers []Identifier, orderURL string) error
+
+	// RemoveOrder removes an order url for an account and identifier set, it is not an error if the order url isn't
+	// present.
+	RemoveOrder(accountURL string, identifiers []Identifier, orderURL string) error
+}
+
+// MemoryOrderIndex is an in-memory OrderIndex. The zero value is ready to use.
+type MemoryOrderIndex struct {
+	mu     sync.Mutex
+	orders map[string][]string
+}
+
+// LookupOrders implements OrderIndex.LookupOrders
+func (idx *MemoryOrderIndex) LookupOrders(accountURL string, identifiers []Identifier) ([]string, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return append([]string(nil), idx.orders[orderIndexKey(accountURL, identifiers)]...), nil
+}
+
+// AddOrder implements OrderIndex.AddOrder
+func (idx *MemoryOrderIndex) AddOrder(accountURL string, identifiers []Identifier, orderURL string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.orders == nil {
+		idx.orders = map[string][]string{}
+	}
+	key := orderIndexKey(accountURL, identifiers)
+	idx.orders[key] = append(idx.orders[key], orderURL)
+	return nil
+}
+
+// RemoveOrder implements OrderIndex.RemoveOrder
+func (idx *MemoryOrderIndex) RemoveOrder(accountURL string, identifiers []Identifier, orderURL string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	key := orderIndexKey(accountURL, identifiers)
+	var urls []string
+	for _, u := range idx.orders[key] {
+		if u != orderURL {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		delete(idx.orders, key)
+		return nil
+	}
+	idx.orders[key] = urls
+	return nil
+}
+
+// Helper function to build an index key from an account url and the normalised, sorted, identifier set.
+func orderIndexKey(accountURL string, identifiers []Identifier) string {
+	ids := identifierSet(identifiers)
+	keys := make([]string, 0, len(ids))
+	for id := range ids {
+		keys = append(keys, id.Type+":"+id.Value)
+	}
+	sort.Strings(keys)
+	return accountURL + " " + strings.Join(keys, ",")
+}
+
+// Helper function to build a set of normalised identifiers. Identifiers which can't be normalised are used as is.
+func identifierSet(identifiers []Identifier) map[Identifier]bool {
+	set := map[Identifier]bool{}
+	for _, id := range identifiers {
+		if normalized, err := NormalizeIdentifier(id); err == nil {
+			id = normalized
+		}
+		set[id] = true
+	}
+	return set
+}
+
+// Helper function to determine whether an order is for exactly the provided identifiers.
+func sameIdentifiers(a, b []Identifier) bool {
+	setA, setB := identifierSet(a), identifierSet(b)
+	if len(setA) != len(setB) {
+		return false
+	}
+	for id := range setA {
+		if !setB[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to determine whether an existing order can be reused, ie it is pending or ready, unexpired and
+// for exactly the provided identifiers.
+func reusableOrder(order Order, identifiers []Identifier) bool {
+	if order.Status != "pending" && order.Status != "ready" {
+		return false
+	}
+	if !order.Expires.IsZero() && order.Expires.Before(time.Now()) {
+		return false
+	}
+	return sameIdentifiers(order.Identifiers, identifiers)
+}
+
+// FindOrder looks for an existing pending or ready order for exactly the provided identifiers, first in the local
+// index if not nil, then in the account order list if provided by the acme server. Orders which can't be fetched
+// are skipped, and removed from the index along with any which can't be reused. Returns false if no order can be
+// reused.
+func (c Client) FindOrder(account Account, identifiers []Identifier, index OrderIndex) (Order, bool, error) {
+	return c.findOrder(account, identifiers, index, true)
+}
+
+// Helper function to find a reusable order, optionally walking the account order list if the local index has none.
+func (c Client) findOrder(account Account, identifiers []Identifier, index OrderIndex, walkOrderList bool) (Order, bool, error) {
+	if index != nil {
+		order, found, err := c.findIndexedOrder(account, identifiers, index)
+		if err != nil || found {
+			return order, found, err
+		}
+	}
+
+	if !walkOrderList || account.Orders == "" {
+		return Order{}, false, nil
+	}
+
+	// fetch each order here rather than in the iterator, so an order which can't be fetched doesn't end the iteration
+	it := c.IterateOrders(account, OrderListFilter{})
+	for it.Next() {
+		order, err := c.FetchOrder(account, it.OrderURL())
+		if err != nil {
+			continue
+		}
+		if reusableOrder(order, identifiers) {
+			return order, true, nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return Order{}, false, err
+	}
+
+	return Order{}, false, nil
+}
+
+// Helper function to look for a reusable order in the local index only. Orders which can't be fetched or reused are
+// removed from the index, so they aren't fetched again by later lookups.
+func (c Client) findIndexedOrder(account Account, identifiers []Identifier, index OrderIndex) (Order, bool, error) {
+	urls, err := index.LookupOrders(account.URL, identifiers)
+	if err != nil {
+		return Order{}, false, fmt.Errorf("acme: error looking up order index: %v", err)
+	}
+	// most recently added orders first
+	for i := len(urls) - 1; i >= 0; i-- {
+		order, err := c.FetchOrder(account, urls[i])
+		if err == nil && reusableOrder(order, identifiers) {
+			return order, true, nil
+		}
+		// the order may have been removed by the server, or finalized, expired or invalid
+		if err := index.RemoveOrder(account.URL, identifiers, urls[i]); err != nil {
+			return Order{}, false, fmt.Errorf("acme: error removing order from index: %v", err)
+		}
+	}
+	return Order{}, false, nil
+}
+
+// ReuseOrder returns an existing pending or ready order for exactly the provided identifiers if one exists, see
+// FindOrder, otherwise creates a new order. New orders are added to the local index if not nil. The returned bool
+// indicates whether an existing order was reused.
+func (c Client) ReuseOrder(account Account, identifiers []Identifier, index OrderIndex) (Order, bool, error) {
+	return c.reuseOrder(account, identifiers, index, true)
+}
+
+// Helper function to reuse or create an order, optionally walking the account order list, see findOrder.
+func (c Client) reuseOrder(account Account, identifiers []Identifier, index OrderIndex, walkOrderList bool) (Order, bool, error) {
+	order, found, err := c.findOrder(account, identifiers, index, walkOrderList)
+	if err != nil {
+		return order, false, err
+	}
+	if found {
+		return order, true, nil
+	}
+
+	order, err = c.NewOrder(account, identifiers)
+	if err != nil {
+		return order, false, err
+	}
+
+	if index != nil {
+		if err := index.AddOrder(account.URL, identifiers, order.URL); err != nil {
+			return order, false, fmt.Errorf("acme: error adding order to index: %v", err)
+		}
+	}
+
+	return order, false, nil
+}
+
+// AuthorizationReport lists the authorizations of an order by whether they are still valid.
+type AuthorizationReport struct {
+	// Valid authorizations which have not expired, each valid until its Expires time.
+	Valid []Authorization
+
+	// Pending authorizations which still need to be fulfilled.
+	Pending []Authorization
+
+	// Invalid authorizations, either expired or with any other status, eg "invalid", "deactivated" or "revoked".
+	Invalid []Authorization
+}
+
+// ValidUntil returns the earliest expiry of the valid authorizations, or the zero time if there are none.
+func (r AuthorizationReport) ValidUntil() time.Time {
+	var until time.Time
+	for _, auth := range r.Valid {
+		if until.IsZero() || (!auth.Expires.IsZero() && auth.Expires.Before(until)) {
+			until = auth.Expires
+		}
+	}
+	return until
+}
+
+// ReportAuthorizations fetches each authorization in an order and reports which are still valid and until when.
+func (c Client) ReportAuthorizations(account Account, order Order) (AuthorizationReport, error) {
+	report := AuthorizationReport{}
+	now := time.Now()
+
+	for _, authURL := range order.Authorizations {
+		auth, err := c.FetchAuthorization(account, authURL)
+		if err != nil {
+			return report, fmt.Errorf("acme: error fetching authorization %q: %v", authURL, err)
+		}
+
+		switch {
+		case !auth.Expires.IsZero() && auth.Expires.Before(now):
+			report.Invalid = append(report.Invalid, auth)
+		case auth.Status == "valid":
+			report.Valid = append(report.Valid, auth)
+		case auth.Status == "pending":
+			report.Pending = append(report.Pending, auth)
+		default:
+			report.Invalid = append(report.Invalid, auth)
+		}
+	}
+
+	return report, nil
+}
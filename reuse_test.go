@@ -0,0 +1,162 @@
+package acme
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryOrderIndex(t *testing.T) {
+	idx := &MemoryOrderIndex{}
+	ids := []Identifier{{Type: "dns", Value: "Example.com."}, {Type: "ip", Value: "::ffff:192.0.2.1"}}
+
+	urls, err := idx.LookupOrders("acct", ids)
+	if err != nil || len(urls) != 0 {
+		t.Fatalf("expected no orders, got: %v %v", urls, err)
+	}
+
+	if err := idx.AddOrder("acct", ids, "https://example.com/order/1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// same identifier set, different order and form
+	urls, _ = idx.LookupOrders("acct", []Identifier{{Type: "ip", Value: "192.0.2.1"}, {Type: "dns", Value: "example.com"}})
+	if len(urls) != 1 || urls[0] != "https://example.com/order/1" {
+		t.Fatalf("expected indexed order, got: %v", urls)
+	}
+
+	if urls, _ = idx.LookupOrders("other", ids); len(urls) != 0 {
+		t.Fatalf("expected no orders for other account, got: %v", urls)
+	}
+	if urls, _ = idx.LookupOrders("acct", ids[:1]); len(urls) != 0 {
+		t.Fatalf("expected no orders for identifier subset, got: %v", urls)
+	}
+
+	if err := idx.AddOrder("acct", ids, "https://example.com/order/2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := idx.RemoveOrder("acct", ids, "https://example.com/order/1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if urls, _ = idx.LookupOrders("acct", ids); len(urls) != 1 || urls[0] != "https://example.com/order/2" {
+		t.Fatalf("expected remaining order, got: %v", urls)
+	}
+	if err := idx.RemoveOrder("acct", ids, "https://example.com/order/1"); err != nil {
+		t.Fatalf("unexpected error removing twice: %v", err)
+	}
+	if err := idx.RemoveOrder("acct", ids, "https://example.com/order/2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if urls, _ = idx.LookupOrders("acct", ids); len(urls) != 0 {
+		t.Fatalf("expected no orders after removal, got: %v", urls)
+	}
+}
+
+func Test_reusableOrder(t *testing.T) {
+	ids := []Identifier{{Type: "dns", Value: "example.com"}}
+	tests := []struct {
+		name     string
+		order    Order
+		reusable bool
+	}{
+		{
+			name:     "pending",
+			order:    Order{Status: "pending", Identifiers: ids},
+			reusable: true,
+		},
+		{
+			name:     "ready unexpired",
+			order:    Order{Status: "ready", Identifiers: ids, Expires: time.Now().Add(time.Hour)},
+			reusable: true,
+		},
+		{
+			name:  "expired",
+			order: Order{Status: "pending", Identifiers: ids, Expires: time.Now().Add(-time.Hour)},
+		},
+		{
+			name:  "valid",
+			order: Order{Status: "valid", Identifiers: ids},
+		},
+		{
+			name:  "extra identifier",
+			order: Order{Status: "pending", Identifiers: append([]Identifier{{Type: "dns", Value: "www.example.com"}}, ids...)},
+		},
+	}
+	for _, ct := range tests {
+		if reusable := reusableOrder(ct.order, ids); reusable != ct.reusable {
+			t.Errorf("%s: expected reusable %t, got %t", ct.name, ct.reusable, reusable)
+		}
+	}
+}
+
+func TestAuthorizationReport_ValidUntil(t *testing.T) {
+	if !(AuthorizationReport{}).ValidUntil().IsZero() {
+		t.Fatal("expected zero time with no valid authorizations")
+	}
+	now := time.Now()
+	report := AuthorizationReport{Valid: []Authorization{{Expires: now.Add(2 * time.Hour)}, {Expires: now.Add(time.Hour)}}}
+	if until := report.ValidUntil(); !until.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected earliest expiry, got: %v", until)
+	}
+}
+
+func TestClient_ReuseOrder(t *testing.T) {
+	account := makeAccount(t)
+	idx := &MemoryOrderIndex{}
+	ids := []Identifier{{Type: "dns", Value: randString() + ".com"}}
+
+	order, reused, err := testClient.ReuseOrder(account, ids, idx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reused {
+		t.Fatal("expected new order, got reused")
+	}
+
+	// orders which can't be fetched are skipped
+	if err := idx.AddOrder(account.URL, ids, order.URL+"-missing"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reusedOrder, reused, err := testClient.ReuseOrder(account, ids, idx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reused || reusedOrder.URL != order.URL {
+		t.Fatalf("expected reused order %s, got: %t %s", order.URL, reused, reusedOrder.URL)
+	}
+
+	// the unfetchable order was pruned from the index
+	if urls, _ := idx.LookupOrders(account.URL, ids); len(urls) != 1 || urls[0] != order.URL {
+		t.Fatalf("expected only the reused order in the index, got: %v", urls)
+	}
+
+	report, err := testClient.ReportAuthorizations(account, reusedOrder)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Pending) != len(ids) || len(report.Valid) != 0 {
+		t.Fatalf("expected pending authorizations, got: %+v", report)
+	}
+}
+
+func TestClient_ReuseOrder2(t *testing.T) {
+	account, finalizedOrder, _ := makeOrderFinalised(t, nil)
+	idx := &MemoryOrderIndex{}
+	if err := idx.AddOrder(account.URL, finalizedOrder.Identifiers, finalizedOrder.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	order, reused, err := testClient.ReuseOrder(account, finalizedOrder.Identifiers, idx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reused || order.URL == finalizedOrder.URL {
+		t.Fatalf("expected new order instead of finalized order, got: %t %s", reused, order.URL)
+	}
+
+	// the finalized order is removed from the index so it isn't fetched again
+	urls, _ := idx.LookupOrders(account.URL, finalizedOrder.Identifiers)
+	if len(urls) != 1 || urls[0] != order.URL {
+		t.Fatalf("expected only the new order in the index, got: %v", urls)
+	}
+}
@@ -0,0 +1,356 @@
+package acme
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TSIGAlgorithmHMACSHA256 is the name of the hmac-sha256 transaction signature algorithm.
+// See https://tools.ietf.org/html/rfc8945#section-6
+const TSIGAlgorithmHMACSHA256 = "hmac-sha256."
+
+const (
+	tsigFudge = 300
+
+	rfc2136DefaultTTL     = 120
+	rfc2136DefaultTimeout = 10 * time.Second
+)
+
+// RFC2136Provider is a DNSProvider which adds and removes TXT records by sending dynamic updates to the primary
+// nameserver of a zone, signed with a TSIG key.
+// See https://tools.ietf.org/html/rfc2136 and https://tools.ietf.org/html/rfc8945
+type RFC2136Provider struct {
+	// Nameserver is the address of the primary nameserver accepting updates, eg "ns1.example.com:53".
+	// The port defaults to 53 if not included.
+	Nameserver string
+
+	// Zone containing the records to update, eg "example.com."
+	// If not set, the zone is found by querying the nameserver for the SOA record of the record name.
+	Zone string
+
+	// TSIGKeyName and TSIGSecret, base64 encoded, of the key used to sign updates with hmac-sha256.
+	// Updates are sent unsigned if no key name is set.
+	TSIGKeyName string
+	TSIGSecret  string
+
+	// TTL of the created TXT records.
+	// Default 120 seconds if not set.
+	TTL time.Duration
+
+	// Timeout for each message exchange with the nameserver.
+	// Default 10 seconds if not set.
+	Timeout time.Duration
+}
+
+// Present adds a TXT record with the value at the fully qualified domain name.
+func (p RFC2136Provider) Present(fqdn, value string) error {
+	ttl := uint32(p.TTL / time.Second)
+	if ttl == 0 {
+		ttl = rfc2136DefaultTTL
+	}
+	return p.update(fqdn, dnsRR{
+		name:  fqdn,
+		typ:   dnsTypeTXT,
+		class: dnsClassINET,
+		ttl:   ttl,
+		data:  dnsTXTData(value),
+	})
+}
+
+// CleanUp deletes the TXT record with the value at the fully qualified domain name.
+func (p RFC2136Provider) CleanUp(fqdn, value string) error {
+	// deleting an rr from an rrset uses class none and a zero ttl
+	// https://tools.ietf.org/html/rfc2136#section-2.5.4
+	return p.update(fqdn, dnsRR{
+		name:  fqdn,
+		typ:   dnsTypeTXT,
+		class: dnsClassNONE,
+		data:  dnsTXTData(value),
+	})
+}
+
+func (p RFC2136Provider) timeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return rfc2136DefaultTimeout
+}
+
+// Helper function to send a single record update to the nameserver.
+func (p RFC2136Provider) update(fqdn string, rr dnsRR) error {
+	if p.Nameserver == "" {
+		return errors.New("acme: no rfc2136 nameserver")
+	}
+	if !strings.HasSuffix(fqdn, ".") {
+		fqdn += "."
+		rr.name = fqdn
+	}
+
+	var key *tsigKey
+	if p.TSIGKeyName != "" {
+		secret, err := base64.StdEncoding.DecodeString(p.TSIGSecret)
+		if err != nil {
+			return fmt.Errorf("acme: error decoding tsig secret: %v", err)
+		}
+		key = &tsigKey{name: p.TSIGKeyName, secret: secret}
+	}
+
+	zone := p.Zone
+	if zone == "" {
+		var err error
+		zone, err = dnsFindZone(p.Nameserver, fqdn, p.timeout())
+		if err != nil {
+			return err
+		}
+	}
+	if !strings.HasSuffix(zone, ".") {
+		zone += "."
+	}
+
+	msg := dnsMsg{
+		id:        dnsMsgID(),
+		flags:     dnsOpcodeFlags(dnsOpcodeUpdate),
+		questions: []dnsQuestion{{name: zone, typ: dnsTypeSOA, class: dnsClassINET}},
+		// the authority section is the update section of an update message
+		authorities: []dnsRR{rr},
+	}
+	query, err := msg.pack()
+	if err != nil {
+		return err
+	}
+
+	var mac []byte
+	if key != nil {
+		query, mac, err = key.sign(query, nil, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := dnsExchange(p.Nameserver, query, p.timeout())
+	if err != nil {
+		return err
+	}
+	m, err := parseDNSMsg(resp)
+	if err != nil {
+		return err
+	}
+	// verify the response before acting on the rcode, so tsig errors are reported and unsigned responses aren't trusted
+	if key != nil {
+		if err := key.verify(resp, mac, time.Now()); err != nil {
+			return fmt.Errorf("acme: dns update of %s in zone %s failed: %s: %v", fqdn, zone, dnsRcodeName(m.rcode()), err)
+		}
+	}
+	if rcode := m.rcode(); rcode != dnsRcodeSuccess {
+		return fmt.Errorf("acme: dns update of %s in zone %s failed: %s", fqdn, zone, dnsRcodeName(rcode))
+	}
+
+	return nil
+}
+
+// Helper function to find the zone containing a name by asking a nameserver for its SOA record.
+func dnsFindZone(nameserver, fqdn string, timeout time.Duration) (string, error) {
+	msg := dnsMsg{
+		id:        dnsMsgID(),
+		flags:     dnsOpcodeFlags(dnsOpcodeQuery),
+		questions: []dnsQuestion{{name: fqdn, typ: dnsTypeSOA, class: dnsClassINET}},
+	}
+	query, err := msg.pack()
+	if err != nil {
+		return "", err
+	}
+	resp, err := dnsExchange(nameserver, query, timeout)
+	if err != nil {
+		return "", err
+	}
+	m, err := parseDNSMsg(resp)
+	if err != nil {
+		return "", err
+	}
+	if rcode := m.rcode(); rcode != dnsRcodeSuccess && rcode != dnsRcodeNXDomain {
+		return "", fmt.Errorf("acme: error finding zone of %s: %s", fqdn, dnsRcodeName(rcode))
+	}
+	// the soa is in the answer section at the zone apex, otherwise the authority section
+	for _, section := range [][]dnsRR{m.answers, m.authorities} {
+		for _, rr := range section {
+			if rr.typ == dnsTypeSOA && dnsIsSubdomain(fqdn, rr.name) {
+				return rr.name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("acme: no zone found for %s", fqdn)
+}
+
+// tsigKey signs and verifies dns messages using hmac-sha256 transaction signatures.
+// See https://tools.ietf.org/html/rfc8945
+type tsigKey struct {
+	name   string
+	secret []byte
+}
+
+// Helper function to compute a message mac over the prior mac, if any, the message without the signature and the
+// tsig variables.
+// https://tools.ietf.org/html/rfc8945#section-4.3.3
+func (k tsigKey) mac(priorMAC, msg []byte, signed time.Time, fudge, tsigErr uint16, other []byte) ([]byte, error) {
+	name, err := packDNSName(strings.ToLower(k.name))
+	if err != nil {
+		return nil, err
+	}
+	alg, _ := packDNSName(TSIGAlgorithmHMACSHA256)
+
+	h := hmac.New(sha256.New, k.secret)
+	if priorMAC != nil {
+		var l [2]byte
+		binary.BigEndian.PutUint16(l[:], uint16(len(priorMAC)))
+		h.Write(l[:])
+		h.Write(priorMAC)
+	}
+	h.Write(msg)
+	h.Write(name)
+	h.Write([]byte{0, dnsClassANY, 0, 0, 0, 0})
+	h.Write(alg)
+	h.Write(tsigTime(signed))
+	var b [6]byte
+	binary.BigEndian.PutUint16(b[0:], fudge)
+	binary.BigEndian.PutUint16(b[2:], tsigErr)
+	binary.BigEndian.PutUint16(b[4:], uint16(len(other)))
+	h.Write(b[:])
+	h.Write(other)
+	return h.Sum(nil), nil
+}
+
+// Helper function to encode the 48 bit time signed.
+func tsigTime(t time.Time) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(t.Unix()))
+	return b[2:]
+}
+
+// sign appends a tsig record to a packed message, returning the signed message and its mac.
+// The prior mac is the request mac when signing a response.
+func (k tsigKey) sign(msg, priorMAC []byte, now time.Time) ([]byte, []byte, error) {
+	if len(msg) < dnsHeaderLen {
+		return nil, nil, errors.New("acme: dns message too short")
+	}
+	mac, err := k.mac(priorMAC, msg, now, tsigFudge, 0, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	alg, _ := packDNSName(TSIGAlgorithmHMACSHA256)
+	data := append([]byte{}, alg...)
+	data = append(data, tsigTime(now)...)
+	data = append(data, byte(tsigFudge>>8), byte(tsigFudge&0xff), byte(len(mac)>>8), byte(len(mac)))
+	data = append(data, mac...)
+	// original id, error and other len
+	data = append(data, msg[0], msg[1], 0, 0, 0, 0)
+
+	rr, err := dnsMsg{additionals: []dnsRR{{
+		name:  strings.ToLower(k.name),
+		typ:   dnsTypeTSIG,
+		class: dnsClassANY,
+		data:  data,
+	}}}.pack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signed := append(append([]byte{}, msg...), rr[dnsHeaderLen:]...)
+	binary.BigEndian.PutUint16(signed[10:], binary.BigEndian.Uint16(signed[10:])+1)
+	return signed, mac, nil
+}
+
+// tsigRecord is the parsed data of a tsig record.
+type tsigRecord struct {
+	algorithm string
+	signed    time.Time
+	fudge     uint16
+	mac       []byte
+	origID    uint16
+	err       uint16
+	other     []byte
+}
+
+// Helper function to parse the data of a tsig record.
+func parseTSIGRecord(msg []byte, rr dnsRR) (tsigRecord, error) {
+	t := tsigRecord{}
+	alg, off, err := readDNSName(msg, rr.dataOff)
+	if err != nil {
+		return t, err
+	}
+	t.algorithm = strings.ToLower(alg)
+	end := rr.dataOff + len(rr.data)
+	if off+10 > end {
+		return t, errors.New("acme: tsig record too short")
+	}
+	var secs [8]byte
+	copy(secs[2:], msg[off:off+6])
+	t.signed = time.Unix(int64(binary.BigEndian.Uint64(secs[:])), 0)
+	t.fudge = binary.BigEndian.Uint16(msg[off+6:])
+	macLen := int(binary.BigEndian.Uint16(msg[off+8:]))
+	off += 10
+	if off+macLen+6 > end {
+		return t, errors.New("acme: tsig record too short")
+	}
+	t.mac = msg[off : off+macLen]
+	off += macLen
+	t.origID = binary.BigEndian.Uint16(msg[off:])
+	t.err = binary.BigEndian.Uint16(msg[off+2:])
+	otherLen := int(binary.BigEndian.Uint16(msg[off+4:]))
+	off += 6
+	if off+otherLen > end {
+		return t, errors.New("acme: tsig record too short")
+	}
+	t.other = msg[off : off+otherLen]
+	return t, nil
+}
+
+// verify checks the tsig record at the end of a signed message. The prior mac is the request mac when verifying a
+// response.
+func (k tsigKey) verify(msg, priorMAC []byte, now time.Time) error {
+	m, err := parseDNSMsg(msg)
+	if err != nil {
+		return err
+	}
+	if len(m.additionals) == 0 || m.additionals[len(m.additionals)-1].typ != dnsTypeTSIG {
+		return errors.New("acme: dns message is not signed")
+	}
+	rr := m.additionals[len(m.additionals)-1]
+	if !dnsNameEqual(rr.name, k.name) {
+		return fmt.Errorf("acme: dns message signed with unexpected key: %s", rr.name)
+	}
+	t, err := parseTSIGRecord(msg, rr)
+	if err != nil {
+		return err
+	}
+	if t.algorithm != TSIGAlgorithmHMACSHA256 {
+		return fmt.Errorf("acme: unsupported tsig algorithm: %s", t.algorithm)
+	}
+	if t.err != 0 {
+		return fmt.Errorf("acme: tsig error: %s", dnsRcodeName(int(t.err)))
+	}
+
+	// the mac covers the message as it was before the tsig record was added
+	unsigned := append([]byte{}, msg[:rr.off]...)
+	binary.BigEndian.PutUint16(unsigned[0:], t.origID)
+	binary.BigEndian.PutUint16(unsigned[10:], uint16(len(m.additionals)-1))
+	mac, err := k.mac(priorMAC, unsigned, t.signed, t.fudge, t.err, t.other)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(mac, t.mac) {
+		return errors.New("acme: invalid tsig signature")
+	}
+
+	if d := now.Sub(t.signed); d > time.Duration(t.fudge)*time.Second || -d > time.Duration(t.fudge)*time.Second {
+		return fmt.Errorf("acme: tsig time signed %s outside fudge of %ds", t.signed, t.fudge)
+	}
+
+	return nil
+}
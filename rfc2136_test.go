@@ -0,0 +1,156 @@
+package acme
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTSIGKey(t *testing.T) {
+	key := tsigKey{name: "Update-Key.", secret: []byte("secret")}
+	msg, _ := dnsMsg{id: 42, questions: []dnsQuestion{{name: "example.com.", typ: dnsTypeSOA, class: dnsClassINET}}}.pack()
+
+	now := time.Now()
+	signed, mac, err := key.sign(msg, nil, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mac) != 32 {
+		t.Fatalf("unexpected mac length: %d", len(mac))
+	}
+	if err := key.verify(signed, nil, now); err != nil {
+		t.Fatalf("unexpected verify error: %v", err)
+	}
+
+	if err := (tsigKey{name: "update-key", secret: []byte("other")}).verify(signed, nil, now); err == nil {
+		t.Fatal("expected bad secret error, got none")
+	}
+	if err := (tsigKey{name: "other-key", secret: []byte("secret")}).verify(signed, nil, now); err == nil {
+		t.Fatal("expected bad key name error, got none")
+	}
+	if err := key.verify(signed, mac, now); err == nil {
+		t.Fatal("expected error verifying with unexpected prior mac, got none")
+	}
+	if err := key.verify(signed, nil, now.Add(time.Hour)); err == nil {
+		t.Fatal("expected time error, got none")
+	}
+	if err := key.verify(msg, nil, now); err == nil {
+		t.Fatal("expected unsigned error, got none")
+	}
+
+	tampered := append([]byte{}, signed...)
+	tampered[dnsHeaderLen+1] = 'E'
+	if err := key.verify(tampered, nil, now); err == nil {
+		t.Fatal("expected tampered error, got none")
+	}
+
+	// responses are signed including the request mac
+	resp, _, err := key.sign(msg, mac, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := key.verify(resp, mac, now); err != nil {
+		t.Fatalf("unexpected verify error: %v", err)
+	}
+	if err := key.verify(resp, nil, now); err == nil {
+		t.Fatal("expected error verifying without request mac, got none")
+	}
+}
+
+func TestRFC2136Provider(t *testing.T) {
+	key := &tsigKey{name: "acme-update.", secret: []byte("0123456789abcdef")}
+	srv := newTestDNSServer(t, "example.com.", key)
+	defer srv.close()
+
+	p := RFC2136Provider{
+		Nameserver:  srv.addr,
+		Zone:        "example.com",
+		TSIGKeyName: "acme-update",
+		TSIGSecret:  base64.StdEncoding.EncodeToString(key.secret),
+		Timeout:     time.Second,
+	}
+
+	fqdn := "_acme-challenge.www.example.com"
+	if err := p.Present(fqdn, "one"); err != nil {
+		t.Fatalf("unexpected error presenting: %v", err)
+	}
+	if err := p.Present(fqdn, "two"); err != nil {
+		t.Fatalf("unexpected error presenting: %v", err)
+	}
+	if values := srv.txt(fqdn); len(values) != 2 || values[0] != "one" || values[1] != "two" {
+		t.Fatalf("unexpected txt values: %v", values)
+	}
+	srv.mu.Lock()
+	ttl := srv.records[0].ttl
+	srv.mu.Unlock()
+	if ttl != rfc2136DefaultTTL {
+		t.Fatalf("unexpected ttl: %d", ttl)
+	}
+
+	if err := p.CleanUp(fqdn, "one"); err != nil {
+		t.Fatalf("unexpected error cleaning up: %v", err)
+	}
+	if values := srv.txt(fqdn); len(values) != 1 || values[0] != "two" {
+		t.Fatalf("unexpected txt values after clean up: %v", values)
+	}
+
+	// zone found from the soa record
+	p.Zone = ""
+	if err := p.CleanUp(fqdn+".", "two"); err != nil {
+		t.Fatalf("unexpected error cleaning up: %v", err)
+	}
+	if values := srv.txt(fqdn); len(values) != 0 {
+		t.Fatalf("unexpected txt values after clean up: %v", values)
+	}
+
+	bad := p
+	bad.TSIGSecret = base64.StdEncoding.EncodeToString([]byte("wrong"))
+	if err := bad.Present(fqdn, "three"); err == nil || !strings.Contains(err.Error(), "NOTAUTH") || !strings.Contains(err.Error(), "BADSIG") {
+		t.Fatalf("expected NOTAUTH BADSIG error, got: %v", err)
+	}
+
+	// unsigned responses aren't trusted
+	unsigned := newTestDNSServer(t, "example.com.", nil)
+	defer unsigned.close()
+	bad = p
+	bad.Nameserver = unsigned.addr
+	if err := bad.Present(fqdn, "three"); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Fatalf("expected unsigned response error, got: %v", err)
+	}
+
+	bad = p
+	bad.TSIGSecret = "not base64!"
+	if err := bad.Present(fqdn, "three"); err == nil {
+		t.Fatal("expected secret decoding error, got none")
+	}
+
+	bad = p
+	bad.Zone = "example.org"
+	if err := bad.Present(fqdn, "three"); err == nil {
+		t.Fatal("expected zone error, got none")
+	}
+
+	if err := (RFC2136Provider{}).Present(fqdn, "three"); err == nil {
+		t.Fatal("expected no nameserver error, got none")
+	}
+}
+
+func Test_dnsFindZone(t *testing.T) {
+	srv := newTestDNSServer(t, "example.com.", nil)
+	defer srv.close()
+
+	for _, name := range []string{"example.com.", "a.b.example.com."} {
+		zone, err := dnsFindZone(srv.addr, name, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error finding zone of %s: %v", name, err)
+		}
+		if zone != "example.com." {
+			t.Fatalf("unexpected zone of %s: %s", name, zone)
+		}
+	}
+
+	if _, err := dnsFindZone(srv.addr, "example.org.", time.Second); err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
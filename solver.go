@@ -0,0 +1,186 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Solver fulfils a challenge so that it can be validated by the acme server.
+type Solver interface {
+	// Present makes the challenge response available to the acme server, eg serving the http-01 key authorization.
+	Present(account Account, auth Authorization, chal Challenge) error
+
+	// CleanUp removes anything provisioned by Present. It is always called once Present has been called, even if
+	// Present or the challenge validation failed.
+	CleanUp(account Account, auth Authorization, chal Challenge) error
+}
+
+// SolverWaiter is optionally implemented by a Solver to wait until a presented challenge response is visible to the
+// acme server, eg for dns propagation, before the challenge is updated.
+type SolverWaiter interface {
+	Wait(account Account, auth Authorization, chal Challenge) error
+}
+
+// SolverPayload is optionally implemented by a Solver for challenge types which require a response payload other
+// than the empty object, eg tkauth-01 or device-attest-01. The payload is used to update the challenge, see
+// UpdateChallengePayload.
+type SolverPayload interface {
+	Payload(account Account, auth Authorization, chal Challenge) (interface{}, error)
+}
+
+// SolverRegistry holds the challenge solvers available to fulfil authorizations, keyed by challenge type, with
+// optional per-identifier overrides. Safe for concurrent use.
+type SolverRegistry struct {
+	mu        sync.RWMutex
+	solvers   map[string]Solver
+	overrides map[Identifier]map[string]Solver
+}
+
+// NewSolverRegistry creates an empty solver registry.
+func NewSolverRegistry() *SolverRegistry {
+	return &SolverRegistry{
+		solvers:   map[string]Solver{},
+		overrides: map[Identifier]map[string]Solver{},
+	}
+}
+
+// Register sets the solver used for a challenge type, for identifiers without an override. A nil solver removes the
+// challenge type.
+func (r *SolverRegistry) Register(challengeType string, solver Solver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if solver == nil {
+		delete(r.solvers, challengeType)
+		return
+	}
+	r.solvers[challengeType] = solver
+}
+
+// RegisterFor sets the solver used for a challenge type for a single identifier, overriding any solver registered
+// for the challenge type. Wildcard overrides are registered with a "*." prefixed dns identifier. A nil solver
+// disables the challenge type for the identifier.
+func (r *SolverRegistry) RegisterFor(identifier Identifier, challengeType string, solver Solver) {
+	if normalized, err := NormalizeIdentifier(identifier); err == nil {
+		identifier = normalized
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.overrides[identifier] == nil {
+		r.overrides[identifier] = map[string]Solver{}
+	}
+	r.overrides[identifier][challengeType] = solver
+}
+
+// SolversFor returns the solvers available for an authorization, keyed by challenge type, applying any overrides for
+// the authorization identifier. Overrides for a wildcard identifier take precedence over the base identifier.
+func (r *SolverRegistry) SolversFor(auth Authorization) map[string]Solver {
+	identifier := auth.Identifier
+	if normalized, err := NormalizeIdentifier(identifier); err == nil {
+		identifier = normalized
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	solvers := map[string]Solver{}
+	for chalType, solver := range r.solvers {
+		solvers[chalType] = solver
+	}
+
+	overrides := []Identifier{identifier}
+	if auth.Wildcard && !strings.HasPrefix(identifier.Value, "*.") {
+		overrides = append(overrides, Identifier{Type: identifier.Type, Value: "*." + identifier.Value})
+	}
+	for _, id := range overrides {
+		for chalType, solver := range r.overrides[id] {
+			if solver == nil {
+				delete(solvers, chalType)
+				continue
+			}
+			solvers[chalType] = solver
+		}
+	}
+
+	return solvers
+}
+
+// SolveAuthorization fulfils a pending authorization with the client solver registry, see WithSolverRegistry. The
+// first challenge type offered in the authorization ChallengeTypes with an available solver is presented, waited on
+// if the solver implements SolverWaiter, and updated with the solver payload if it implements SolverPayload. The
+// solver is always cleaned up once presented.
+// Returns the updated challenge, or an empty challenge if the authorization is already valid.
+func (c Client) SolveAuthorization(account Account, auth Authorization) (Challenge, error) {
+	if c.solvers == nil {
+		return Challenge{}, errors.New("acme: no solver registry, see WithSolverRegistry")
+	}
+
+	if auth.Status == "valid" {
+		return Challenge{}, nil
+	}
+	if auth.Status != "pending" {
+		return Challenge{}, fmt.Errorf("acme: unexpected authorization status %q for %s", auth.Status, auth.Identifier.Value)
+	}
+
+	chal, solver, ok := pickSolver(auth, c.solvers.SolversFor(auth), "")
+	if !ok {
+		return Challenge{}, fmt.Errorf("acme: no solver for authorization %s challenges: %v", auth.Identifier.Value, auth.ChallengeTypes)
+	}
+
+	return c.solveChallenge(account, auth, chal, solver)
+}
+
+// Helper function to pick the preferred challenge type if offered and solvable, otherwise the first challenge offered
+// in an authorization with a matching solver.
+func pickSolver(auth Authorization, solvers map[string]Solver, preferred string) (Challenge, Solver, bool) {
+	if solver, ok := solvers[preferred]; ok && solver != nil {
+		if chal, ok := auth.ChallengeMap[preferred]; ok {
+			return chal, solver, true
+		}
+	}
+	for _, chalType := range auth.ChallengeTypes {
+		solver, ok := solvers[chalType]
+		if !ok || solver == nil {
+			continue
+		}
+		return auth.ChallengeMap[chalType], solver, true
+	}
+	return Challenge{}, nil, false
+}
+
+// Helper function to present, optionally wait for, and update a challenge, always cleaning up once presented.
+func (c Client) solveChallenge(account Account, auth Authorization, chal Challenge, solver Solver) (updated Challenge, err error) {
+	defer func() {
+		cleanupErr := solver.CleanUp(account, auth, chal)
+		if cleanupErr != nil && err == nil {
+			err = fmt.Errorf("acme: error cleaning up %s challenge for %s: %v", chal.Type, auth.Identifier.Value, cleanupErr)
+		}
+	}()
+
+	if err := solver.Present(account, auth, chal); err != nil {
+		return chal, fmt.Errorf("acme: error presenting %s challenge for %s: %v", chal.Type, auth.Identifier.Value, err)
+	}
+
+	if waiter, ok := solver.(SolverWaiter); ok {
+		if err := waiter.Wait(account, auth, chal); err != nil {
+			return chal, fmt.Errorf("acme: error waiting for %s challenge for %s: %v", chal.Type, auth.Identifier.Value, err)
+		}
+	}
+
+	var payload interface{} = struct{}{}
+	if p, ok := solver.(SolverPayload); ok {
+		payload, err = p.Payload(account, auth, chal)
+		if err != nil {
+			return chal, fmt.Errorf("acme: error building %s challenge response for %s: %v", chal.Type, auth.Identifier.Value, err)
+		}
+	}
+
+	updated, err = c.UpdateChallengePayload(account, chal, payload)
+	if err != nil {
+		return updated, fmt.Errorf("acme: error updating %s challenge for %s: %v", chal.Type, auth.Identifier.Value, err)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,125 @@
+package acme
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testWaitSolver is a testSolver which also implements SolverWaiter
+type testWaitSolver struct {
+	testSolver
+	waitErr error
+	waited  int
+}
+
+func (s *testWaitSolver) Wait(account Account, auth Authorization, chal Challenge) error {
+	s.waited++
+	return s.waitErr
+}
+
+// testPayloadSolver is a testSolver which also implements SolverPayload
+type testPayloadSolver struct {
+	testSolver
+	payloadErr error
+}
+
+func (s *testPayloadSolver) Payload(account Account, auth Authorization, chal Challenge) (interface{}, error) {
+	return nil, s.payloadErr
+}
+
+func TestSolverRegistry_SolversFor(t *testing.T) {
+	http01 := &testSolver{}
+	dns01 := &testSolver{}
+	override := &testSolver{}
+	wildcard := &testSolver{}
+
+	r := NewSolverRegistry()
+	r.Register(ChallengeTypeHTTP01, http01)
+	r.Register(ChallengeTypeDNS01, dns01)
+	r.Register(ChallengeTypeTLSALPN01, &testSolver{})
+	r.Register(ChallengeTypeTLSALPN01, nil)
+	r.RegisterFor(Identifier{Type: "dns", Value: "Special.example.com"}, ChallengeTypeDNS01, override)
+	r.RegisterFor(Identifier{Type: "dns", Value: "special.example.com"}, ChallengeTypeHTTP01, nil)
+	r.RegisterFor(Identifier{Type: "dns", Value: "*.example.com"}, ChallengeTypeDNS01, wildcard)
+
+	solvers := r.SolversFor(Authorization{Identifier: Identifier{Type: "dns", Value: "other.com"}})
+	if len(solvers) != 2 || solvers[ChallengeTypeHTTP01] != http01 || solvers[ChallengeTypeDNS01] != dns01 {
+		t.Fatalf("unexpected default solvers: %v", solvers)
+	}
+
+	solvers = r.SolversFor(Authorization{Identifier: Identifier{Type: "dns", Value: "special.example.com"}})
+	if len(solvers) != 1 || solvers[ChallengeTypeDNS01] != override {
+		t.Fatalf("unexpected overridden solvers: %v", solvers)
+	}
+
+	solvers = r.SolversFor(Authorization{Identifier: Identifier{Type: "dns", Value: "example.com"}, Wildcard: true})
+	if solvers[ChallengeTypeDNS01] != wildcard {
+		t.Fatalf("unexpected wildcard solvers: %v", solvers)
+	}
+	solvers = r.SolversFor(Authorization{Identifier: Identifier{Type: "dns", Value: "example.com"}})
+	if solvers[ChallengeTypeDNS01] != dns01 {
+		t.Fatalf("unexpected non-wildcard solvers: %v", solvers)
+	}
+}
+
+func TestClient_SolveAuthorization(t *testing.T) {
+	if _, err := testClient.SolveAuthorization(Account{}, Authorization{}); err == nil {
+		t.Fatal("expected error with no registry, got none")
+	}
+
+	r := NewSolverRegistry()
+	client := testClient
+	if err := WithSolverRegistry(r)(&client); err != nil {
+		t.Fatalf("unexpected error setting registry: %v", err)
+	}
+
+	if chal, err := client.SolveAuthorization(Account{}, Authorization{Status: "valid"}); err != nil || chal.Type != "" {
+		t.Fatalf("expected nothing to solve for valid authorization, got: %+v %v", chal, err)
+	}
+	if _, err := client.SolveAuthorization(Account{}, Authorization{Status: "invalid"}); err == nil {
+		t.Fatal("expected error for invalid authorization, got none")
+	}
+
+	account, order := makeOrder(t)
+	auth, err := client.FetchAuthorization(account, order.Authorizations[0])
+	if err != nil {
+		t.Fatalf("unexpected error fetching authorization: %v", err)
+	}
+
+	if _, err := client.SolveAuthorization(account, auth); err == nil || !strings.Contains(err.Error(), "no solver") {
+		t.Fatalf("expected no solver error, got: %v", err)
+	}
+
+	failing := &testWaitSolver{waitErr: errors.New("ALWAYS ERRORS")}
+	r.RegisterFor(auth.Identifier, ChallengeTypeHTTP01, failing)
+	if _, err := client.SolveAuthorization(account, auth); err == nil || !strings.Contains(err.Error(), "ALWAYS") {
+		t.Fatalf("expected wait error, got: %v", err)
+	}
+	if failing.presented != 1 || failing.waited != 1 || failing.cleaned != 1 {
+		t.Fatalf("expected present, wait and clean up, got: %d %d %d", failing.presented, failing.waited, failing.cleaned)
+	}
+
+	payload := &testPayloadSolver{payloadErr: errors.New("ALWAYS ERRORS")}
+	r.RegisterFor(auth.Identifier, ChallengeTypeHTTP01, payload)
+	if _, err := client.SolveAuthorization(account, auth); err == nil || !strings.Contains(err.Error(), "ALWAYS") {
+		t.Fatalf("expected payload error, got: %v", err)
+	}
+	if payload.presented != 1 || payload.cleaned != 1 {
+		t.Fatalf("expected present and clean up, got: %d %d", payload.presented, payload.cleaned)
+	}
+
+	solver := &testWaitSolver{}
+	r.Register(ChallengeTypeHTTP01, solver)
+	r.RegisterFor(auth.Identifier, ChallengeTypeHTTP01, solver)
+	chal, err := client.SolveAuthorization(account, auth)
+	if err != nil {
+		t.Fatalf("unexpected error solving authorization: %v", err)
+	}
+	if chal.Status != "valid" {
+		t.Fatalf("expected valid challenge, got: %s", chal.Status)
+	}
+	if solver.presented != 1 || solver.waited != 1 || solver.cleaned != 1 {
+		t.Fatalf("expected present, wait and clean up, got: %d %d %d", solver.presented, solver.waited, solver.cleaned)
+	}
+}
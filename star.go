@@ -0,0 +1,134 @@
+package acme
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Helper function to check a requested auto-renewal object against the directory STAR capabilities and build the
+// new order request field.
+func (c Client) autoRenewalRequest(ar AutoRenewal) (interface{}, error) {
+	meta := c.dir.Meta.AutoRenewal
+	if meta == nil {
+		return nil, ErrAutoRenewalNotSupported
+	}
+
+	if ar.EndDate.IsZero() {
+		return nil, errors.New("acme: auto-renewal end-date is required")
+	}
+	if ar.EndDate.Before(time.Now()) {
+		return nil, fmt.Errorf("acme: auto-renewal end-date is in the past: %v", ar.EndDate)
+	}
+	start := ar.StartDate
+	if start.IsZero() {
+		start = time.Now()
+	}
+	if !ar.EndDate.After(start) {
+		return nil, fmt.Errorf("acme: auto-renewal end-date (%v) is not after start-date (%v)", ar.EndDate, start)
+	}
+	if meta.MaxDuration > 0 && ar.EndDate.Sub(start) > time.Duration(meta.MaxDuration)*time.Second {
+		return nil, fmt.Errorf("acme: auto-renewal duration exceeds max-duration of %d seconds", meta.MaxDuration)
+	}
+	if ar.Lifetime <= 0 {
+		return nil, errors.New("acme: auto-renewal lifetime is required")
+	}
+	if ar.Lifetime < meta.MinLifetime {
+		return nil, fmt.Errorf("acme: auto-renewal lifetime %d is less than min-lifetime of %d seconds", ar.Lifetime, meta.MinLifetime)
+	}
+	if ar.LifetimeAdjust < 0 {
+		return nil, fmt.Errorf("acme: auto-renewal lifetime-adjust is negative: %d", ar.LifetimeAdjust)
+	}
+	if ar.AllowCertificateGet && !meta.AllowCertificateGet {
+		return nil, errors.New("acme: auto-renewal allow-certificate-get not supported by directory")
+	}
+
+	autoRenewalReq := struct {
+		StartDate           string `json:"start-date,omitempty"`
+		EndDate             string `json:"end-date"`
+		Lifetime            int    `json:"lifetime"`
+		LifetimeAdjust      int    `json:"lifetime-adjust,omitempty"`
+		AllowCertificateGet bool   `json:"allow-certificate-get,omitempty"`
+	}{
+		EndDate:             ar.EndDate.UTC().Format(time.RFC3339),
+		Lifetime:            ar.Lifetime,
+		LifetimeAdjust:      ar.LifetimeAdjust,
+		AllowCertificateGet: ar.AllowCertificateGet,
+	}
+	if !ar.StartDate.IsZero() {
+		autoRenewalReq.StartDate = ar.StartDate.UTC().Format(time.RFC3339)
+	}
+
+	return autoRenewalReq, nil
+}
+
+// NewStarOrder initiates a new STAR order, where the acme server automatically issues short-term certificates at
+// the order StarCertificate url until the auto-renewal end-date or the order is canceled. Essentially a helper
+// function.
+// See https://tools.ietf.org/html/rfc8739#section-2.1
+func (c Client) NewStarOrder(account Account, identifiers []Identifier, autoRenewal AutoRenewal) (Order, error) {
+	return c.ReplacementOrderExtension(account, nil, identifiers, OrderExtension{AutoRenewal: &autoRenewal})
+}
+
+// FetchStarCertificate downloads the current certificate chain of a valid STAR order. If the order allows
+// unauthenticated GET requests and no account private key is provided, the certificate is fetched with a GET request,
+// otherwise POST-as-GET is used.
+// See https://tools.ietf.org/html/rfc8739#section-3.3
+func (c Client) FetchStarCertificate(account Account, order Order) ([]*x509.Certificate, error) {
+	if order.StarCertificate == "" {
+		return nil, errors.New("acme: no star-certificate url in order")
+	}
+
+	if account.PrivateKey == nil && order.AutoRenewal != nil && order.AutoRenewal.AllowCertificateGet {
+		return c.getCertificates(order.StarCertificate, map[string]bool{})
+	}
+
+	return c.FetchCertificates(account, order.StarCertificate)
+}
+
+// Helper function to fetch a certificate chain with unauthenticated GET requests, following any "up" links with GET
+// requests too as there is no account key to sign POST-as-GET requests.
+func (c Client) getCertificates(certificateURL string, seen map[string]bool) ([]*x509.Certificate, error) {
+	if seen[certificateURL] {
+		return nil, fmt.Errorf("acme: certificate url %q already fetched", certificateURL)
+	}
+	seen[certificateURL] = true
+
+	resp, body, err := c.getRaw(certificateURL, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := decodeCertificates(body)
+	if err != nil {
+		return certs, err
+	}
+
+	if up := fetchLink(resp, "up"); up != "" {
+		upCerts, err := c.getCertificates(up, seen)
+		if err != nil {
+			return certs, fmt.Errorf("acme: error fetching up cert: %v", err)
+		}
+		certs = append(certs, upCerts...)
+	}
+
+	return certs, nil
+}
+
+// CancelOrder cancels a STAR order, stopping the acme server from issuing further certificates.
+// See https://tools.ietf.org/html/rfc8739#section-2.3
+func (c Client) CancelOrder(account Account, order Order) (Order, error) {
+	cancelReq := struct {
+		Status string `json:"status"`
+	}{
+		Status: "canceled",
+	}
+
+	cancelResp := Order{URL: order.URL}
+	if _, err := c.post(order.URL, account.URL, account.PrivateKey, cancelReq, &cancelResp, http.StatusOK); err != nil {
+		return order, err
+	}
+
+	return cancelResp, nil
+}
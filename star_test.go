@@ -0,0 +1,163 @@
+package acme
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_autoRenewalRequest(t *testing.T) {
+	c := Client{}
+	if _, err := c.autoRenewalRequest(AutoRenewal{}); err != ErrAutoRenewalNotSupported {
+		t.Fatalf("expected not supported error, got: %v", err)
+	}
+
+	c.dir.Meta.AutoRenewal = &AutoRenewalMeta{MinLifetime: 3600, MaxDuration: 86400 * 30}
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		autoRenewal AutoRenewal
+		errorString string
+	}{
+		{
+			name:        "no end date",
+			autoRenewal: AutoRenewal{Lifetime: 3600},
+			errorString: "end-date is required",
+		},
+		{
+			name:        "end date in past",
+			autoRenewal: AutoRenewal{EndDate: now.Add(-time.Hour), Lifetime: 3600},
+			errorString: "in the past",
+		},
+		{
+			name:        "end before start",
+			autoRenewal: AutoRenewal{StartDate: now.Add(48 * time.Hour), EndDate: now.Add(24 * time.Hour), Lifetime: 3600},
+			errorString: "not after start-date",
+		},
+		{
+			name:        "exceeds max duration",
+			autoRenewal: AutoRenewal{EndDate: now.Add(31 * 24 * time.Hour), Lifetime: 3600},
+			errorString: "max-duration",
+		},
+		{
+			name:        "no lifetime",
+			autoRenewal: AutoRenewal{EndDate: now.Add(24 * time.Hour)},
+			errorString: "lifetime is required",
+		},
+		{
+			name:        "short lifetime",
+			autoRenewal: AutoRenewal{EndDate: now.Add(24 * time.Hour), Lifetime: 60},
+			errorString: "min-lifetime",
+		},
+		{
+			name:        "certificate get not allowed",
+			autoRenewal: AutoRenewal{EndDate: now.Add(24 * time.Hour), Lifetime: 3600, AllowCertificateGet: true},
+			errorString: "allow-certificate-get",
+		},
+		{
+			name:        "valid",
+			autoRenewal: AutoRenewal{EndDate: now.Add(24 * time.Hour), Lifetime: 3600, LifetimeAdjust: 60},
+		},
+	}
+
+	for _, ct := range tests {
+		_, err := c.autoRenewalRequest(ct.autoRenewal)
+		if ct.errorString == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", ct.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), ct.errorString) {
+			t.Errorf("%s: expected error containing %q, got: %v", ct.name, ct.errorString, err)
+		}
+	}
+
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	req, err := c.autoRenewalRequest(AutoRenewal{StartDate: start, EndDate: start.Add(24 * time.Hour), Lifetime: 3600})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling request: %v", err)
+	}
+	expected := `{"start-date":"2030-01-01T00:00:00Z","end-date":"2030-01-02T00:00:00Z","lifetime":3600}`
+	if string(b) != expected {
+		t.Fatalf("expected %s, got %s", expected, string(b))
+	}
+}
+
+func TestClient_NewStarOrder(t *testing.T) {
+	if testClient.Directory().Meta.AutoRenewal != nil {
+		t.Skip("acme server supports STAR")
+		return
+	}
+	account := makeAccount(t)
+	_, err := testClient.NewStarOrder(account, []Identifier{{Type: "dns", Value: randString() + ".com"}}, AutoRenewal{
+		EndDate:  time.Now().Add(24 * time.Hour),
+		Lifetime: 3600,
+	})
+	if err != ErrAutoRenewalNotSupported {
+		t.Fatalf("expected not supported error, got: %v", err)
+	}
+}
+
+func TestClient_FetchStarCertificate(t *testing.T) {
+	if _, err := testClient.FetchStarCertificate(Account{}, Order{}); err == nil {
+		t.Fatal("expected error with no star-certificate url, got none")
+	}
+}
+
+func TestClient_FetchStarCertificate2(t *testing.T) {
+	var ders [][]byte
+	for _, name := range []string{"leaf.example.com", "issuer.example.com"} {
+		cert, err := TLSALPN01Certificate(Identifier{Type: "dns", Value: name}, "keyauth")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ders = append(ders, cert.Certificate[0])
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("expected GET request, got: %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/star":
+			w.Header().Add("Link", "<"+srv.URL+"/issuer>;rel=\"up\"")
+			pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: ders[0]})
+		case "/issuer":
+			pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: ders[1]})
+		case "/loop":
+			w.Header().Add("Link", "<"+srv.URL+"/loop>;rel=\"up\"")
+			pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: ders[0]})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := Client{httpClient: srv.Client()}
+	order := Order{StarCertificate: srv.URL + "/star", AutoRenewal: &AutoRenewal{AllowCertificateGet: true}}
+
+	// up links are followed with GET requests, as there is no account key to sign POST-as-GET requests
+	certs, err := client.FetchStarCertificate(Account{}, order)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(certs) != 2 || certs[0].DNSNames[0] != "leaf.example.com" || certs[1].DNSNames[0] != "issuer.example.com" {
+		t.Fatalf("expected leaf and issuer certificates, got: %d", len(certs))
+	}
+
+	order.StarCertificate = srv.URL + "/loop"
+	if _, err := client.FetchStarCertificate(Account{}, order); err == nil {
+		t.Fatal("expected error with up link loop, got none")
+	}
+}
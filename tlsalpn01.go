@@ -0,0 +1,189 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ALPNProtoTLSALPN01 is the application layer protocol negotiated by the acme server when validating a tls-alpn-01
+// challenge.
+// See https://tools.ietf.org/html/rfc8737#section-6.2
+const ALPNProtoTLSALPN01 = "acme-tls/1"
+
+// oidACMEIdentifier is the id-pe-acmeIdentifier certificate extension.
+// See https://tools.ietf.org/html/rfc8737#section-6.1
+var oidACMEIdentifier = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
+// TLSALPN01Certificate creates the self-signed validation certificate for a tls-alpn-01 challenge, containing the
+// critical acmeIdentifier extension with the SHA-256 digest of the key authorization. The certificate has a single
+// subject alternative name of the dns identifier, or of the ip address for ip identifiers which are validated using
+// the reverse DNS name as the server name, see TLSALPN01ServerName.
+// See https://tools.ietf.org/html/rfc8737#section-3 and https://tools.ietf.org/html/rfc8738#section-6
+func TLSALPN01Certificate(identifier Identifier, keyAuth string) (*tls.Certificate, error) {
+	if keyAuth == "" {
+		return nil, errors.New("acme: no key authorization provided")
+	}
+
+	digest := sha256.Sum256([]byte(keyAuth))
+	extValue, err := asn1.Marshal(digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("acme: error encoding acme identifier extension: %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("acme: error generating serial number: %v", err)
+	}
+
+	now := time.Now()
+	tpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "ACME tls-alpn-01 challenge"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidACMEIdentifier, Critical: true, Value: extValue},
+		},
+	}
+
+	switch identifier.Type {
+	case IdentifierTypeDNS:
+		tpl.DNSNames = []string{identifier.Value}
+	case IdentifierTypeIP:
+		ip := net.ParseIP(identifier.Value)
+		if ip == nil {
+			return nil, fmt.Errorf("acme: invalid ip identifier: %q", identifier.Value)
+		}
+		tpl.IPAddresses = []net.IP{ip}
+	default:
+		return nil, fmt.Errorf("acme: unsupported identifier type for tls-alpn-01: %q", identifier.Type)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("acme: error generating certificate key: %v", err)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("acme: error creating certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("acme: error parsing certificate: %v", err)
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// TLSALPN01Responder is a Solver for tls-alpn-01 challenges which answers acme-tls/1 handshakes with validation
+// certificates from a tls server, passing ordinary handshakes through to the application certificates. See
+// GetCertificate and TLSConfig. The zero value is ready to use.
+type TLSALPN01Responder struct {
+	mu    sync.RWMutex
+	certs map[string]*tls.Certificate
+}
+
+// Present creates the validation certificate for the challenge and serves it for the identifier server name.
+func (r *TLSALPN01Responder) Present(account Account, auth Authorization, chal Challenge) error {
+	serverName, err := TLSALPN01ServerName(auth.Identifier)
+	if err != nil {
+		return err
+	}
+	cert, err := TLSALPN01Certificate(auth.Identifier, chal.KeyAuthorization)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.certs == nil {
+		r.certs = map[string]*tls.Certificate{}
+	}
+	r.certs[strings.ToLower(serverName)] = cert
+	return nil
+}
+
+// CleanUp stops serving the validation certificate for the identifier server name.
+func (r *TLSALPN01Responder) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	serverName, err := TLSALPN01ServerName(auth.Identifier)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.certs, strings.ToLower(serverName))
+	return nil
+}
+
+// GetCertificate wraps a tls.Config.GetCertificate hook, answering acme-tls/1 handshakes with a presented validation
+// certificate and passing any other handshake to next. If next is nil, nil is returned for other handshakes so the
+// tls.Config Certificates are used.
+func (r *TLSALPN01Responder) GetCertificate(next func(*tls.ClientHelloInfo) (*tls.Certificate, error)) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if !isTLSALPN01Hello(hello) {
+			if next == nil {
+				return nil, nil
+			}
+			return next(hello)
+		}
+
+		serverName := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+		r.mu.RLock()
+		cert, ok := r.certs[serverName]
+		r.mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("acme: no tls-alpn-01 challenge for %q", serverName)
+		}
+		return cert, nil
+	}
+}
+
+// TLSConfig returns a copy of a tls config, or a new config if nil, which answers tls-alpn-01 challenges: the
+// acme-tls/1 protocol is appended to NextProtos and GetCertificate is wrapped, see GetCertificate.
+func (r *TLSALPN01Responder) TLSConfig(config *tls.Config) *tls.Config {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+
+	hasProto := false
+	for _, proto := range config.NextProtos {
+		if proto == ALPNProtoTLSALPN01 {
+			hasProto = true
+			break
+		}
+	}
+	if !hasProto {
+		config.NextProtos = append(config.NextProtos, ALPNProtoTLSALPN01)
+	}
+
+	config.GetCertificate = r.GetCertificate(config.GetCertificate)
+	return config
+}
+
+// Helper function to determine whether a handshake is a tls-alpn-01 validation, ie only offering acme-tls/1.
+func isTLSALPN01Hello(hello *tls.ClientHelloInfo) bool {
+	return len(hello.SupportedProtos) == 1 && hello.SupportedProtos[0] == ALPNProtoTLSALPN01
+}
@@ -0,0 +1,133 @@
+package acme
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"net"
+	"testing"
+)
+
+// Helper function to fetch the acmeIdentifier extension digest from a certificate.
+func tlsalpn01Digest(t *testing.T, cert *x509.Certificate) []byte {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidACMEIdentifier) {
+			continue
+		}
+		if !ext.Critical {
+			t.Fatal("expected critical acme identifier extension")
+		}
+		var digest []byte
+		if _, err := asn1.Unmarshal(ext.Value, &digest); err != nil {
+			t.Fatalf("unexpected error parsing extension: %v", err)
+		}
+		return digest
+	}
+	t.Fatal("no acme identifier extension")
+	return nil
+}
+
+func TestTLSALPN01Certificate(t *testing.T) {
+	if _, err := TLSALPN01Certificate(Identifier{Type: "dns", Value: "example.com"}, ""); err == nil {
+		t.Fatal("expected error with no key authorization, got none")
+	}
+	if _, err := TLSALPN01Certificate(Identifier{Type: "email", Value: "a@example.com"}, "x"); err == nil {
+		t.Fatal("expected error with unsupported identifier, got none")
+	}
+
+	keyAuth := "token.thumbprint"
+	expected := sha256.Sum256([]byte(keyAuth))
+
+	cert, err := TLSALPN01Certificate(Identifier{Type: "dns", Value: "example.com"}, keyAuth)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cert.Leaf.DNSNames) != 1 || cert.Leaf.DNSNames[0] != "example.com" {
+		t.Fatalf("unexpected dns names: %v", cert.Leaf.DNSNames)
+	}
+	if !bytes.Equal(tlsalpn01Digest(t, cert.Leaf), expected[:]) {
+		t.Fatal("unexpected key authorization digest")
+	}
+
+	cert, err = TLSALPN01Certificate(Identifier{Type: "ip", Value: "2001:db8::1"}, keyAuth)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cert.Leaf.IPAddresses) != 1 || !cert.Leaf.IPAddresses[0].Equal(net.ParseIP("2001:db8::1")) || len(cert.Leaf.DNSNames) != 0 {
+		t.Fatalf("unexpected subject alternative names: %v %v", cert.Leaf.IPAddresses, cert.Leaf.DNSNames)
+	}
+}
+
+func TestTLSALPN01Responder(t *testing.T) {
+	appCert, err := TLSALPN01Certificate(Identifier{Type: "dns", Value: "app.example.com"}, "app")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := &TLSALPN01Responder{}
+	config := r.TLSConfig(&tls.Config{Certificates: []tls.Certificate{*appCert}, NextProtos: []string{"http/1.1"}})
+	if len(config.NextProtos) != 2 || config.NextProtos[1] != ALPNProtoTLSALPN01 {
+		t.Fatalf("unexpected next protos: %v", config.NextProtos)
+	}
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatalf("unexpected error listening: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	dial := func(serverName string, protos ...string) (*x509.Certificate, error) {
+		conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{
+			ServerName:         serverName,
+			NextProtos:         protos,
+			InsecureSkipVerify: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0], nil
+	}
+
+	auth := Authorization{Identifier: Identifier{Type: "ip", Value: "192.0.2.1"}}
+	chal := Challenge{KeyAuthorization: "token.thumbprint"}
+	if err := r.Present(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cert, err := dial("1.2.0.192.in-addr.arpa", ALPNProtoTLSALPN01)
+	if err != nil {
+		t.Fatalf("unexpected error in validation handshake: %v", err)
+	}
+	expected := sha256.Sum256([]byte(chal.KeyAuthorization))
+	if !bytes.Equal(tlsalpn01Digest(t, cert), expected[:]) {
+		t.Fatal("unexpected key authorization digest")
+	}
+
+	cert, err = dial("1.2.0.192.in-addr.arpa", "http/1.1")
+	if err != nil {
+		t.Fatalf("unexpected error in ordinary handshake: %v", err)
+	}
+	if len(cert.DNSNames) != 1 || cert.DNSNames[0] != "app.example.com" {
+		t.Fatalf("expected application certificate, got: %v", cert.DNSNames)
+	}
+
+	if err := r.CleanUp(Account{}, auth, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := dial("1.2.0.192.in-addr.arpa", ALPNProtoTLSALPN01); err == nil {
+		t.Fatal("expected error in validation handshake after clean up, got none")
+	}
+}
@@ -0,0 +1,203 @@
+package acme
+
+import (
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// oidTNAuthList is the TNAuthList certificate extension, id-pe-TNAuthList.
+// See https://tools.ietf.org/html/rfc8226#section-9
+var oidTNAuthList = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 26}
+
+// TNEntry is a single entry in a TNAuthList, exactly one of SPC, Range or One must be set.
+// See https://tools.ietf.org/html/rfc8226#section-9
+type TNEntry struct {
+	// SPC is a service provider code.
+	SPC string
+
+	// Range is a range of telephone numbers.
+	Range *TNRange
+
+	// One is a single telephone number.
+	One string
+}
+
+// TNRange is a range of Count telephone numbers, starting at Start.
+type TNRange struct {
+	Start string
+	Count int
+}
+
+// asn1 structure of a TelephoneNumberRange
+type tnRange struct {
+	Start string `asn1:"ia5"`
+	Count int
+}
+
+// NewTNAuthListIdentifier creates a TNAuthList identifier from a list of entries, encoding the value as the base64
+// encoded DER TNAuthList.
+// See https://tools.ietf.org/html/rfc9448#section-3
+func NewTNAuthListIdentifier(entries ...TNEntry) (Identifier, error) {
+	der, err := marshalTNAuthList(entries)
+	if err != nil {
+		return Identifier{}, err
+	}
+	return Identifier{Type: IdentifierTypeTNAuthList, Value: base64.StdEncoding.EncodeToString(der)}, nil
+}
+
+// ParseTNAuthList decodes the entries of a TNAuthList identifier value.
+func ParseTNAuthList(value string) ([]TNEntry, error) {
+	der, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("acme: error decoding TNAuthList: %v", err)
+	}
+
+	var raw []asn1.RawValue
+	if rest, err := asn1.Unmarshal(der, &raw); err != nil {
+		return nil, fmt.Errorf("acme: error parsing TNAuthList: %v", err)
+	} else if len(rest) > 0 {
+		return nil, errors.New("acme: trailing data after TNAuthList")
+	}
+
+	var entries []TNEntry
+	for _, r := range raw {
+		if r.Class != asn1.ClassContextSpecific {
+			return nil, fmt.Errorf("acme: unexpected TNAuthList entry class: %d", r.Class)
+		}
+		var entry TNEntry
+		var err error
+		switch r.Tag {
+		case 0:
+			_, err = asn1.UnmarshalWithParams(r.Bytes, &entry.SPC, "ia5")
+		case 1:
+			var rng tnRange
+			_, err = asn1.Unmarshal(r.Bytes, &rng)
+			entry.Range = &TNRange{Start: rng.Start, Count: rng.Count}
+		case 2:
+			_, err = asn1.UnmarshalWithParams(r.Bytes, &entry.One, "ia5")
+		default:
+			err = fmt.Errorf("unknown tag %d", r.Tag)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("acme: error parsing TNAuthList entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Helper function to encode a TNAuthList as DER. Entries use explicit tagging as per the RFC 8226 asn1 module.
+func marshalTNAuthList(entries []TNEntry) ([]byte, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("acme: TNAuthList requires at least one entry")
+	}
+
+	var raw []asn1.RawValue
+	for _, entry := range entries {
+		set := 0
+		var tag int
+		var inner []byte
+		var err error
+		if entry.SPC != "" {
+			set++
+			tag = 0
+			inner, err = asn1.MarshalWithParams(entry.SPC, "ia5")
+		}
+		if entry.Range != nil {
+			set++
+			tag = 1
+			if err = checkTelephoneNumber(entry.Range.Start); err == nil {
+				if entry.Range.Count < 2 {
+					err = fmt.Errorf("range count must be at least 2, got %d", entry.Range.Count)
+				} else {
+					inner, err = asn1.Marshal(tnRange{Start: entry.Range.Start, Count: entry.Range.Count})
+				}
+			}
+		}
+		if entry.One != "" {
+			set++
+			tag = 2
+			if err = checkTelephoneNumber(entry.One); err == nil {
+				inner, err = asn1.MarshalWithParams(entry.One, "ia5")
+			}
+		}
+		if set != 1 {
+			return nil, errors.New("acme: TNAuthList entry must have exactly one of SPC, Range or One")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("acme: error encoding TNAuthList entry: %v", err)
+		}
+		raw = append(raw, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: tag, IsCompound: true, Bytes: inner})
+	}
+
+	return asn1.Marshal(raw)
+}
+
+// Helper function to check a telephone number is 1 to 15 characters of "0123456789#*".
+func checkTelephoneNumber(tn string) error {
+	if len(tn) < 1 || len(tn) > 15 {
+		return fmt.Errorf("telephone number %q must be 1 to 15 characters", tn)
+	}
+	if strings.Trim(tn, "0123456789#*") != "" {
+		return fmt.Errorf("telephone number %q contains invalid characters", tn)
+	}
+	return nil
+}
+
+// UpdateTKAuthChallenge responds to a tkauth-01 challenge with an Authority Token, eg obtained from the challenge
+// TokenAuthority, and waits for the challenge to be validated as per UpdateChallenge.
+// See https://tools.ietf.org/html/rfc9447#section-3 and https://tools.ietf.org/html/rfc9448#section-5
+func (c Client) UpdateTKAuthChallenge(account Account, challenge Challenge, token string) (Challenge, error) {
+	if token == "" {
+		return challenge, errors.New("acme: no authority token provided")
+	}
+	return c.UpdateChallengePayload(account, challenge, tkauthPayload(token))
+}
+
+// Helper function to build the tkauth-01 challenge response payload.
+func tkauthPayload(token string) interface{} {
+	return struct {
+		ATC string `json:"atc"`
+	}{
+		ATC: token,
+	}
+}
+
+// TKAuth01Solver is a Solver for tkauth-01 challenges, responding with an Authority Token obtained for each
+// challenge, eg from the challenge TokenAuthority.
+type TKAuth01Solver struct {
+	// Token returns the Authority Token for a challenge.
+	Token func(account Account, auth Authorization, chal Challenge) (string, error)
+}
+
+// Present does nothing, the token is provided in the challenge response, see Payload.
+func (s TKAuth01Solver) Present(account Account, auth Authorization, chal Challenge) error {
+	if s.Token == nil {
+		return errors.New("acme: no authority token function")
+	}
+	return nil
+}
+
+// CleanUp does nothing.
+func (s TKAuth01Solver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	return nil
+}
+
+// Payload returns the challenge response containing the Authority Token, implementing SolverPayload.
+func (s TKAuth01Solver) Payload(account Account, auth Authorization, chal Challenge) (interface{}, error) {
+	if s.Token == nil {
+		return nil, errors.New("acme: no authority token function")
+	}
+	token, err := s.Token(account, auth, chal)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, errors.New("acme: no authority token provided")
+	}
+	return tkauthPayload(token), nil
+}
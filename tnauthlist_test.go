@@ -0,0 +1,116 @@
+package acme
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNewTNAuthListIdentifier(t *testing.T) {
+	badEntries := [][]TNEntry{
+		nil,
+		{{}},
+		{{SPC: "1234", One: "15551234567"}},
+		{{One: "not a number"}},
+		{{One: "1234567890123456"}},
+		{{Range: &TNRange{Start: "15551230000", Count: 1}}},
+	}
+	for _, entries := range badEntries {
+		if _, err := NewTNAuthListIdentifier(entries...); err == nil {
+			t.Errorf("expected error for entries %+v, got none", entries)
+		}
+	}
+
+	id, err := NewTNAuthListIdentifier(TNEntry{SPC: "1234"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id.Type != IdentifierTypeTNAuthList {
+		t.Fatalf("unexpected identifier type: %s", id.Type)
+	}
+	// SEQUENCE { [0] EXPLICIT IA5String "1234" }
+	expected := []byte{0x30, 0x08, 0xa0, 0x06, 0x16, 0x04, '1', '2', '3', '4'}
+	if der, _ := base64.StdEncoding.DecodeString(id.Value); !bytes.Equal(der, expected) {
+		t.Fatalf("unexpected encoding, expected %x, got %x", expected, der)
+	}
+
+	entries := []TNEntry{
+		{SPC: "1234"},
+		{Range: &TNRange{Start: "15551230000", Count: 100}},
+		{One: "15551234567"},
+	}
+	id, err = NewTNAuthListIdentifier(entries...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := ParseTNAuthList(id.Value)
+	if err != nil {
+		t.Fatalf("unexpected error parsing: %v", err)
+	}
+	if !reflect.DeepEqual(entries, parsed) {
+		t.Fatalf("expected %+v, got %+v", entries, parsed)
+	}
+
+	if _, err := ParseTNAuthList("!!!"); err == nil {
+		t.Fatal("expected error parsing bad base64, got none")
+	}
+}
+
+func Test_createCSR_TNAuthList(t *testing.T) {
+	id, err := NewTNAuthListIdentifier(TNEntry{SPC: "1234"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	csr, err := createCSR([]Identifier{id}, makePrivateKey(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	der, _ := base64.StdEncoding.DecodeString(id.Value)
+	for _, ext := range csr.Extensions {
+		if ext.Id.Equal(oidTNAuthList) {
+			if !bytes.Equal(ext.Value, der) {
+				t.Fatalf("unexpected extension value: %x", ext.Value)
+			}
+			return
+		}
+	}
+	t.Fatal("no TNAuthList extension in csr")
+}
+
+func TestClient_UpdateTKAuthChallenge(t *testing.T) {
+	if _, err := testClient.UpdateTKAuthChallenge(Account{}, Challenge{}, ""); err == nil {
+		t.Fatal("expected error with no token, got none")
+	}
+}
+
+func TestTKAuth01Solver_Payload(t *testing.T) {
+	if _, err := (TKAuth01Solver{}).Payload(Account{}, Authorization{}, Challenge{}); err == nil {
+		t.Fatal("expected error with no token function, got none")
+	}
+
+	empty := TKAuth01Solver{Token: func(account Account, auth Authorization, chal Challenge) (string, error) {
+		return "", nil
+	}}
+	if _, err := empty.Payload(Account{}, Authorization{}, Challenge{}); err == nil {
+		t.Fatal("expected error with no token, got none")
+	}
+
+	solver := TKAuth01Solver{Token: func(account Account, auth Authorization, chal Challenge) (string, error) {
+		return "atc-" + chal.Token, nil
+	}}
+	payload, err := solver.Payload(Account{}, Authorization{}, Challenge{Token: "abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("unexpected error marshalling payload: %v", err)
+	}
+	if string(b) != `{"atc":"atc-abc"}` {
+		t.Fatalf("unexpected payload: %s", b)
+	}
+}
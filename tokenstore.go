@@ -0,0 +1,339 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHTTP01TokenTTL is the time a http-01 token is kept in a HTTP01TokenStore if no expiry is provided.
+const DefaultHTTP01TokenTTL = time.Hour
+
+// HTTP01TokenStore stores http-01 challenge key authorizations keyed by token, so they can be served by any node
+// sharing the store, eg behind a load balancer. See HTTP01Handler.
+type HTTP01TokenStore interface {
+	// PutToken stores the key authorization for a token until it expires.
+	PutToken(token, keyAuth string, expires time.Time) error
+
+	// GetToken returns the key authorization for a token, or ErrHTTP01TokenNotFound if not present or expired.
+	GetToken(token string) (string, error)
+
+	// DeleteToken removes a token, it is not an error if the token isn't present.
+	DeleteToken(token string) error
+}
+
+// Helper function to provide the default expiry of a token if not set.
+func tokenExpiry(expires time.Time) time.Time {
+	if expires.IsZero() {
+		return time.Now().Add(DefaultHTTP01TokenTTL)
+	}
+	return expires
+}
+
+type memoryToken struct {
+	keyAuth string
+	expires time.Time
+}
+
+// MemoryTokenStore is an in-process HTTP01TokenStore. Expired tokens are removed whenever a token is stored.
+// The zero value is ready to use.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]memoryToken
+}
+
+// PutToken implements HTTP01TokenStore.PutToken
+func (s *MemoryTokenStore) PutToken(token, keyAuth string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tokens == nil {
+		s.tokens = map[string]memoryToken{}
+	}
+	now := time.Now()
+	for k, v := range s.tokens {
+		if v.expires.Before(now) {
+			delete(s.tokens, k)
+		}
+	}
+	s.tokens[token] = memoryToken{keyAuth: keyAuth, expires: tokenExpiry(expires)}
+	return nil
+}
+
+// GetToken implements HTTP01TokenStore.GetToken
+func (s *MemoryTokenStore) GetToken(token string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tokens[token]
+	if !ok || t.expires.Before(time.Now()) {
+		return "", ErrHTTP01TokenNotFound
+	}
+	return t.keyAuth, nil
+}
+
+// DeleteToken implements HTTP01TokenStore.DeleteToken
+func (s *MemoryTokenStore) DeleteToken(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, token)
+	return nil
+}
+
+// FileTokenStore is a HTTP01TokenStore keeping each token in a file in a directory, eg on a shared network
+// filesystem. Each file contains the expiry as a unix timestamp on the first line, followed by the key authorization.
+// Expired token files are removed whenever a token is stored, or when read.
+type FileTokenStore struct {
+	// Dir is the directory token files are stored in, created if it doesn't exist.
+	Dir string
+}
+
+// PutToken implements HTTP01TokenStore.PutToken
+func (s FileTokenStore) PutToken(token, keyAuth string, expires time.Time) error {
+	path, err := s.tokenPath(token)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("acme: error creating token store directory %q: %v", s.Dir, err)
+	}
+	s.removeExpired()
+
+	data := strconv.FormatInt(tokenExpiry(expires).Unix(), 10) + "\n" + keyAuth
+
+	// write to a temporary file and rename so readers on other nodes never see a partial token
+	tmp, err := ioutil.TempFile(s.Dir, ".token")
+	if err != nil {
+		return fmt.Errorf("acme: error creating token file: %v", err)
+	}
+	if _, err := tmp.WriteString(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("acme: error writing token file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("acme: error writing token file: %v", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("acme: error setting token file mode: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("acme: error renaming token file: %v", err)
+	}
+	return nil
+}
+
+// GetToken implements HTTP01TokenStore.GetToken
+func (s FileTokenStore) GetToken(token string) (string, error) {
+	path, err := s.tokenPath(token)
+	if err != nil {
+		return "", ErrHTTP01TokenNotFound
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", ErrHTTP01TokenNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("acme: error reading token file: %v", err)
+	}
+
+	keyAuth, expired, err := parseStoredToken(b)
+	if err != nil {
+		return "", err
+	}
+	if expired {
+		os.Remove(path)
+		return "", ErrHTTP01TokenNotFound
+	}
+	return keyAuth, nil
+}
+
+// DeleteToken implements HTTP01TokenStore.DeleteToken
+func (s FileTokenStore) DeleteToken(token string) error {
+	path, err := s.tokenPath(token)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("acme: error removing token file: %v", err)
+	}
+	return nil
+}
+
+// Helper function to remove expired token files from the store directory, so tokens which are never read don't
+// accumulate. Best effort, files which can't be read or aren't token files are left as is.
+func (s FileTokenStore) removeExpired() {
+	infos, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return
+	}
+	for _, fi := range infos {
+		// skip temporary files being written
+		if !fi.Mode().IsRegular() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(s.Dir, fi.Name())
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if _, expired, err := parseStoredToken(b); err == nil && expired {
+			os.Remove(path)
+		}
+	}
+}
+
+// Helper function to build the file path of a token.
+func (s FileTokenStore) tokenPath(token string) (string, error) {
+	if s.Dir == "" {
+		return "", errors.New("acme: no token store directory provided")
+	}
+	if err := checkHTTP01Token(token); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Dir, token), nil
+}
+
+// Helper function to parse a stored token value of the expiry unix timestamp and key authorization.
+func parseStoredToken(b []byte) (string, bool, error) {
+	parts := strings.SplitN(string(b), "\n", 2)
+	if len(parts) != 2 {
+		return "", false, errors.New("acme: invalid stored token")
+	}
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return "", false, fmt.Errorf("acme: invalid stored token expiry: %v", err)
+	}
+	return parts[1], time.Unix(expires, 0).Before(time.Now()), nil
+}
+
+// KeyValueStore is a minimal key/value store interface, eg implemented on top of redis, etcd or a database, used by
+// KeyValueTokenStore.
+type KeyValueStore interface {
+	// Get returns the value of a key, or nil if not present.
+	Get(key string) ([]byte, error)
+
+	// Set stores a value for a key, which may be removed by the store after the ttl.
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Delete removes a key.
+	Delete(key string) error
+}
+
+// KeyValueTokenStore is a HTTP01TokenStore backed by a KeyValueStore. The expiry is passed to the store as a ttl, and
+// also stored with the value, so stores which don't support expiry still don't serve stale tokens.
+type KeyValueTokenStore struct {
+	Store KeyValueStore
+
+	// Prefix is prepended to tokens to build store keys, optional.
+	Prefix string
+}
+
+// PutToken implements HTTP01TokenStore.PutToken
+func (s KeyValueTokenStore) PutToken(token, keyAuth string, expires time.Time) error {
+	expires = tokenExpiry(expires)
+	value := strconv.FormatInt(expires.Unix(), 10) + "\n" + keyAuth
+	if err := s.Store.Set(s.Prefix+token, []byte(value), time.Until(expires)); err != nil {
+		return fmt.Errorf("acme: error storing token: %v", err)
+	}
+	return nil
+}
+
+// GetToken implements HTTP01TokenStore.GetToken
+func (s KeyValueTokenStore) GetToken(token string) (string, error) {
+	b, err := s.Store.Get(s.Prefix + token)
+	if err != nil {
+		return "", fmt.Errorf("acme: error fetching token: %v", err)
+	}
+	if b == nil {
+		return "", ErrHTTP01TokenNotFound
+	}
+	keyAuth, expired, err := parseStoredToken(b)
+	if err != nil {
+		return "", err
+	}
+	if expired {
+		return "", ErrHTTP01TokenNotFound
+	}
+	return keyAuth, nil
+}
+
+// DeleteToken implements HTTP01TokenStore.DeleteToken
+func (s KeyValueTokenStore) DeleteToken(token string) error {
+	if err := s.Store.Delete(s.Prefix + token); err != nil {
+		return fmt.Errorf("acme: error deleting token: %v", err)
+	}
+	return nil
+}
+
+// HTTP01Handler serves http-01 key authorizations from a token store under HTTP01ChallengePath, passing any other
+// requests to the next handler. If next is nil, other requests are not found.
+func HTTP01Handler(store HTTP01TokenStore, next http.Handler) http.Handler {
+	if next == nil {
+		next = http.NotFoundHandler()
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, HTTP01ChallengePath) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := strings.TrimPrefix(r.URL.Path, HTTP01ChallengePath)
+		if checkHTTP01Token(token) != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		keyAuth, err := store.GetToken(token)
+		if err == ErrHTTP01TokenNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte(keyAuth))
+	})
+}
+
+// HTTP01StoreSolver is a Solver for http-01 challenges which puts key authorizations in a token store, to be served
+// by HTTP01Handler on any node sharing the store.
+type HTTP01StoreSolver struct {
+	Store HTTP01TokenStore
+
+	// TTL of stored tokens.
+	// Default DefaultHTTP01TokenTTL if not set.
+	TTL time.Duration
+}
+
+// Present stores the challenge key authorization.
+func (s HTTP01StoreSolver) Present(account Account, auth Authorization, chal Challenge) error {
+	if err := checkHTTP01Token(chal.Token); err != nil {
+		return err
+	}
+	ttl := s.TTL
+	if ttl <= 0 {
+		ttl = DefaultHTTP01TokenTTL
+	}
+	return s.Store.PutToken(chal.Token, chal.KeyAuthorization, time.Now().Add(ttl))
+}
+
+// CleanUp removes the challenge key authorization from the store.
+func (s HTTP01StoreSolver) CleanUp(account Account, auth Authorization, chal Challenge) error {
+	return s.Store.DeleteToken(chal.Token)
+}
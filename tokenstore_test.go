@@ -0,0 +1,155 @@
+package acme
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryKeyValueStore is a KeyValueStore which ignores ttls
+type memoryKeyValueStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (s *memoryKeyValueStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key], nil
+}
+
+func (s *memoryKeyValueStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = map[string][]byte{}
+	}
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryKeyValueStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+func testTokenStore(t *testing.T, name string, store HTTP01TokenStore) {
+	if _, err := store.GetToken("missing"); err != ErrHTTP01TokenNotFound {
+		t.Fatalf("%s: expected not found error, got: %v", name, err)
+	}
+
+	if err := store.PutToken("token1", "token1.thumbprint", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("%s: unexpected error: %v", name, err)
+	}
+	if keyAuth, err := store.GetToken("token1"); err != nil || keyAuth != "token1.thumbprint" {
+		t.Fatalf("%s: expected key authorization, got: %q %v", name, keyAuth, err)
+	}
+
+	if err := store.PutToken("token2", "token2.thumbprint", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("%s: unexpected error: %v", name, err)
+	}
+	if _, err := store.GetToken("token2"); err != ErrHTTP01TokenNotFound {
+		t.Fatalf("%s: expected expired token not found, got: %v", name, err)
+	}
+
+	if err := store.DeleteToken("token1"); err != nil {
+		t.Fatalf("%s: unexpected error: %v", name, err)
+	}
+	if _, err := store.GetToken("token1"); err != ErrHTTP01TokenNotFound {
+		t.Fatalf("%s: expected deleted token not found, got: %v", name, err)
+	}
+	if err := store.DeleteToken("token1"); err != nil {
+		t.Fatalf("%s: unexpected error deleting twice: %v", name, err)
+	}
+}
+
+func TestHTTP01TokenStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokens")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	testTokenStore(t, "memory", &MemoryTokenStore{})
+	testTokenStore(t, "file", FileTokenStore{Dir: dir})
+	testTokenStore(t, "keyvalue", KeyValueTokenStore{Store: &memoryKeyValueStore{}, Prefix: "acme/"})
+
+	if err := (FileTokenStore{Dir: dir}).PutToken("../escape", "x", time.Time{}); err == nil {
+		t.Fatal("expected error with path separator in token, got none")
+	}
+}
+
+func TestFileTokenStore_removeExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokens")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := FileTokenStore{Dir: dir}
+	if err := store.PutToken("expired", "expired.thumbprint", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other := filepath.Join(dir, "other")
+	if err := ioutil.WriteFile(other, []byte("not a token"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// storing another token removes the expired, never read, token
+	if err := store.PutToken("token1", "token1.thumbprint", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "expired")); !os.IsNotExist(err) {
+		t.Fatalf("expected expired token file removed, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "token1")); err != nil {
+		t.Fatalf("expected token file, got: %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("expected other file left as is, got: %v", err)
+	}
+}
+
+func TestHTTP01Handler(t *testing.T) {
+	store := &MemoryTokenStore{}
+	solver := HTTP01StoreSolver{Store: store}
+	chal := Challenge{Token: "token1", KeyAuthorization: "token1.thumbprint"}
+	if err := solver.Present(Account{}, Authorization{}, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := HTTP01Handler(store, next)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	if w := serve(HTTP01ChallengePath + "token1"); w.Code != http.StatusOK || w.Body.String() != chal.KeyAuthorization {
+		t.Fatalf("expected key authorization, got: %d %s", w.Code, w.Body.String())
+	}
+	if w := serve(HTTP01ChallengePath + "unknown"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not found, got: %d", w.Code)
+	}
+	if w := serve("/other"); w.Code != http.StatusTeapot {
+		t.Fatalf("expected next handler, got: %d", w.Code)
+	}
+
+	if err := solver.CleanUp(Account{}, Authorization{}, chal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w := serve(HTTP01ChallengePath + "token1"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not found after clean up, got: %d", w.Code)
+	}
+}
@@ -16,6 +16,21 @@ var (
 	// renewal info entry isn't present on the acme directory (ie, it's not
 	// supported by the acme server)
 	ErrRenewalInfoNotSupported = errors.New("renewal information endpoint not supported")
+
+	// ErrNewAuthzNotSupported is returned by Client.NewAuthorization if the newAuthz entry isn't present on the acme
+	// directory (ie, pre-authorization isn't supported by the acme server)
+	ErrNewAuthzNotSupported = errors.New("acme: pre-authorization (newAuthz) not supported")
+
+	// ErrOrderProcessing is returned by Client.WaitForOrder if the order is still processing once the timeout
+	// expires. The returned order contains a RetryAfter time indicating when to try again.
+	ErrOrderProcessing = errors.New("acme: order is still processing")
+
+	// ErrHTTP01TokenNotFound is returned by a HTTP01TokenStore if a token isn't present or has expired.
+	ErrHTTP01TokenNotFound = errors.New("acme: http-01 token not found")
+
+	// ErrAutoRenewalNotSupported is returned when requesting a STAR order if the acme directory meta doesn't include
+	// an auto-renewal object (ie, STAR isn't supported by the acme server)
+	ErrAutoRenewalNotSupported = errors.New("acme: auto-renewal (STAR) not supported")
 )
 
 // Different possible challenge types provided by an ACME server.
@@ -27,6 +42,15 @@ const (
 	ChallengeTypeHTTP01       = "http-01"
 	ChallengeTypeTLSALPN01    = "tls-alpn-01"
 
+	// See https://tools.ietf.org/html/rfc8823
+	ChallengeTypeEmailReply00 = "email-reply-00"
+
+	// See https://tools.ietf.org/html/rfc9447
+	ChallengeTypeTKAuth01 = "tkauth-01"
+
+	// See https://datatracker.ietf.org/doc/html/draft-ietf-acme-device-attest
+	ChallengeTypeDeviceAttest01 = "device-attest-01"
+
 	// ChallengeTypeTLSSNI01 is deprecated and should not be used.
 	// See: https://community.letsencrypt.org/t/important-what-you-need-to-know-about-tls-sni-validation-issues/50811
 	ChallengeTypeTLSSNI01 = "tls-sni-01"
@@ -68,6 +92,9 @@ type Directory struct {
 		CaaIdentities           []string          `json:"caaIdentities"`
 		ExternalAccountRequired bool              `json:"externalAccountRequired"`
 		Profiles                map[string]string `json:"profiles"`
+
+		// https://tools.ietf.org/html/rfc8739#section-3.1.1
+		AutoRenewal *AutoRenewalMeta `json:"auto-renewal,omitempty"`
 	} `json:"meta"`
 
 	// Directory url provided when creating a new acme client.
@@ -83,6 +110,13 @@ type Client struct {
 	userAgentSuffix string
 	acceptLanguage  string
 	retryCount      int
+	defaultProfile  string
+
+	// expected certificate lifetimes of profiles, keyed by profile name
+	profileLifetimes map[string]time.Duration
+
+	// challenge solvers used by SolveAuthorization and ObtainCertificate
+	solvers *SolverRegistry
 
 	// The amount of total time the Client will wait at most for a challenge to be updated or a certificate to be issued.
 	// Default 30 seconds if duration is not set or if set to 0.
@@ -107,6 +141,10 @@ type Account struct {
 	Contact []string `json:"contact"`
 	Orders  string   `json:"orders"`
 
+	// Delegations is the url of the account delegation list, if provided by the acme server.
+	// See https://tools.ietf.org/html/rfc9115#section-2.3.1
+	Delegations string `json:"delegations,omitempty"`
+
 	// Provided by the Location http header when creating a new account or fetching an existing account.
 	URL string `json:"-"`
 
@@ -121,6 +159,10 @@ type Account struct {
 	// ExternalAccountBinding is populated when using the NewAcctOptExternalAccountBinding option for NewAccountOption
 	// and is otherwise empty. Not populated when account is fetched or created otherwise.
 	ExternalAccountBinding ExternalAccountBinding `json:"-"`
+
+	// Extensions contains any fields returned by the server in the account object which aren't otherwise
+	// decoded into the Account structure, eg CA-specific fields.
+	Extensions map[string]json.RawMessage `json:"-"`
 }
 
 // ExternalAccountBinding holds the key identifier and mac key provided for use in servers that support/require
@@ -152,7 +194,7 @@ type Order struct {
 	Status         string       `json:"status"`
 	Expires        time.Time    `json:"expires"`
 	Identifiers    []Identifier `json:"identifiers"`
-	Profile        string       `json:"Profile,omitempty"`
+	Profile        string       `json:"profile,omitempty"`
 	NotBefore      time.Time    `json:"notBefore"`
 	NotAfter       time.Time    `json:"notAfter"`
 	Error          Problem      `json:"error"`
@@ -171,6 +213,47 @@ type Order struct {
 	// previously-issued certificate which this order is intended to replace.
 	// See https://datatracker.ietf.org/doc/html/draft-ietf-acme-ari-03#section-5
 	Replaces string `json:"replaces,omitempty"`
+
+	// AutoRenewal and StarCertificate are present on STAR orders.
+	// See https://tools.ietf.org/html/rfc8739#section-3.1.2
+	AutoRenewal     *AutoRenewal `json:"auto-renewal,omitempty"`
+	StarCertificate string       `json:"star-certificate,omitempty"`
+
+	// Delegation is the url of the delegation configuration used for a delegated order.
+	// See https://tools.ietf.org/html/rfc9115#section-2.3.2
+	Delegation string `json:"delegation,omitempty"`
+}
+
+// AutoRenewalMeta describes the STAR capabilities of an acme server, advertised in the directory meta object.
+// See https://tools.ietf.org/html/rfc8739#section-3.1.1
+type AutoRenewalMeta struct {
+	// MinLifetime is the minimum certificate lifetime, in seconds.
+	MinLifetime int `json:"min-lifetime"`
+
+	// MaxDuration is the maximum duration of an auto-renewal order, from start-date to end-date, in seconds.
+	MaxDuration int `json:"max-duration"`
+
+	// AllowCertificateGet indicates whether the server supports unauthenticated GET of STAR certificates.
+	AllowCertificateGet bool `json:"allow-certificate-get"`
+}
+
+// AutoRenewal object requested in, and returned with, a STAR order.
+// See https://tools.ietf.org/html/rfc8739#section-3.1.1
+type AutoRenewal struct {
+	// StartDate is the earliest date of validity of the first certificate, optional.
+	StartDate time.Time `json:"start-date"`
+
+	// EndDate is the latest date of validity of the last certificate.
+	EndDate time.Time `json:"end-date"`
+
+	// Lifetime is the validity period of each certificate, in seconds.
+	Lifetime int `json:"lifetime"`
+
+	// LifetimeAdjust is the amount of time, in seconds, each certificate is pre-dated to allow for clock skew, optional.
+	LifetimeAdjust int `json:"lifetime-adjust,omitempty"`
+
+	// AllowCertificateGet requests the STAR certificate be fetchable with an unauthenticated GET request, optional.
+	AllowCertificateGet bool `json:"allow-certificate-get,omitempty"`
 }
 
 // Authorization object returned when fetching an authorization in an order.
@@ -214,6 +297,17 @@ type Challenge struct {
 	// https://datatracker.ietf.org/doc/html/draft-ietf-acme-dns-persist-01#section-3.1
 	IssuerDomainNames []string `json:"issuer-domain-names,omitempty"`
 
+	// From is specific to the email-reply-00 challenge type. It is the email address the challenge email is sent
+	// from. For more information see:
+	// https://tools.ietf.org/html/rfc8823#section-3.1
+	From string `json:"from,omitempty"`
+
+	// TKAuthType and TokenAuthority are specific to the tkauth-01 challenge type. They are the type of Authority
+	// Token required, eg "atc", and the url of the token authority it may be obtained from. For more information see:
+	// https://tools.ietf.org/html/rfc9447#section-3
+	TKAuthType     string `json:"tkauth-type,omitempty"`
+	TokenAuthority string `json:"token-authority,omitempty"`
+
 	// Authorization url provided by the rel="up" Link http header
 	AuthorizationURL string `json:"-"`
 }
@@ -227,6 +321,16 @@ type OrderList struct {
 	Next string `json:"-"`
 }
 
+// DelegationList of delegation objects.
+// See https://tools.ietf.org/html/rfc9115#section-2.3.1
+type DelegationList struct {
+	Delegations []string `json:"delegations"`
+
+	// Delegation list pagination, url to next delegations.
+	// Provided by the rel="next" Link http header
+	Next string `json:"-"`
+}
+
 // NewAccountRequest object used for submitting a request for a new account.
 // Primarily used with NewAccountOptionFunc
 type NewAccountRequest struct {
@@ -236,6 +340,19 @@ type NewAccountRequest struct {
 	ExternalAccountBinding json.RawMessage `json:"externalAccountBinding"`
 }
 
+// UpdateAccountRequest object used for submitting an update to an existing account.
+// Primarily used with UpdateAccountOptionFunc
+type UpdateAccountRequest struct {
+	// Contact replaces the account contacts if not nil. An empty, non-nil slice clears the contacts.
+	Contact []string
+
+	// TermsOfServiceAgreed is only sent if true.
+	TermsOfServiceAgreed bool
+
+	// Extensions contains any additional CA-specific fields to be sent in the update request.
+	Extensions map[string]interface{}
+}
+
 // RenewalInfo stores the server-provided suggestions on when to renew
 // certificates.
 type RenewalInfo struct {
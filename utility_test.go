@@ -6,11 +6,9 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	crand "crypto/rand"
-	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
-	"encoding/base32"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
@@ -426,15 +424,12 @@ func preChallenge(acct Account, auth Authorization, chal Challenge) {
 		doPost("set-txt", setReq)
 
 	case ChallengeTypeDNSAccount01:
-		acctHash := sha256.Sum256([]byte(acct.URL))
-		acctLabel := strings.ToLower(base32.StdEncoding.EncodeToString(acctHash[0:10]))
 		setReq := struct {
 			Host  string `json:"host"`
 			Value string `json:"value"`
 		}{
-			Host: "_" + acctLabel + "._acme-challenge." +
-				auth.Identifier.Value + ".",
-			Value: EncodeDNS01KeyAuthorization(chal.KeyAuthorization),
+			Host:  DNSAccount01RecordName(acct.URL, auth.Identifier.Value),
+			Value: EncodeDNSAccount01KeyAuthorization(chal.KeyAuthorization),
 		}
 		doPost("set-txt", setReq)
 
@@ -475,10 +470,7 @@ func postChallenge(acct Account, auth Authorization, chal Challenge) {
 		doPost("clear-txt", clearReq)
 
 	case ChallengeTypeDNSAccount01:
-		acctHash := sha256.Sum256([]byte(acct.URL))
-		acctLabel := strings.ToLower(base32.StdEncoding.EncodeToString(acctHash[0:10]))
-		host := "_" + acctLabel + "._acme-challenge." +
-			auth.Identifier.Value + "."
+		host := DNSAccount01RecordName(acct.URL, auth.Identifier.Value)
 		clearReq := struct {
 			Host string `json:"host"`
 		}{